@@ -1,14 +1,22 @@
 use colored::Colorize;
+use std::env;
+use std::fs;
+use std::io::{self, Write};
 use std::path::{Path, PathBuf};
 use std::process::{Command, Stdio};
 
 use crate::git::{
-    add_worktree, branch_exists, discover_repo, normalize_tracking_reference_input, project_root,
-    tracked_branch_name, RepoContext,
+    add_worktree, add_worktree_with, apply_stash, branch_exists, discover_repo, find_remote_branch,
+    get_default_branch, get_worktree, is_stash_entry, list_worktrees,
+    normalize_tracking_reference_input, project_root, push_branch, remote_url, repo_path,
+    resolve_revision, tracked_branch_name, RepoContext, StashOutcome, WorktreeLock,
 };
+use crate::models::{AddOptions, Worktree};
 use crate::utils::{
-    default_worktree_name_seed, generate_default_worktree_name, read_repo_config,
-    sanitize_branch_prefix, BootstrapCommand, RepoConfig, DEFAULT_WORKTREE_NAME_ATTEMPTS,
+    branch_glob_matches, default_worktree_name_seed, generate_default_worktree_name,
+    load_repo_config, parse_remote_url, pull_request_url, render_issue_branch_name,
+    sanitize_branch_prefix, slugify_branch, trim_trailing_branch_slashes, BootstrapCommand,
+    RepoConfig, CASE_INSENSITIVE_FS, DEFAULT_WORKTREE_NAME_ATTEMPTS,
 };
 
 #[derive(Debug)]
@@ -24,7 +32,34 @@ struct WorktreeSpec {
     branch_name: String,
 }
 
-pub fn run(name: Option<&str>, track: Option<&str>) {
+/// Where `grove add` writes. Progress goes to stdout, or to stderr with
+/// `--quiet` so that stdout carries only the new worktree's path.
+struct AddOutput<'a> {
+    stdout: &'a mut dyn Write,
+    stderr: &'a mut dyn Write,
+    quiet: bool,
+    /// Write bootstrap commands' output here too instead of letting them
+    /// inherit the terminal, for worktrees set up in parallel.
+    capture: bool,
+}
+
+impl AddOutput<'_> {
+    fn status(&mut self) -> &mut dyn Write {
+        if self.quiet {
+            &mut *self.stderr
+        } else {
+            &mut *self.stdout
+        }
+    }
+
+    /// Print a progress line. Output is best-effort; a closed pipe must not
+    /// abort a worktree that was already created.
+    fn line(&mut self, text: impl std::fmt::Display) {
+        let _ = writeln!(self.status(), "{}", text);
+    }
+}
+
+pub fn run(options: &AddOptions) {
     let repo = match discover_repo() {
         Ok(m) => m,
         Err(e) => {
@@ -33,22 +68,101 @@ pub fn run(name: Option<&str>, track: Option<&str>) {
         }
     };
 
-    let project_root = project_root(&repo);
-    let repo_config = match read_repo_config(project_root) {
+    let mut stdout = io::stdout();
+    let mut stderr = io::stderr();
+    add(
+        &repo,
+        options,
+        &mut AddOutput {
+            stdout: &mut stdout,
+            stderr: &mut stderr,
+            quiet: options.quiet,
+            capture: false,
+        },
+    );
+}
+
+fn add(repo: &RepoContext, options: &AddOptions, output: &mut AddOutput) {
+    let name = options.name.as_deref();
+    let track = options.track.as_deref();
+    let project_root = project_root(repo);
+    let repo_config = match load_repo_config(project_root) {
         Ok(config) => config,
         Err(e) => {
             eprintln!("{} {}", "Error:".red(), e);
             std::process::exit(1);
         }
     };
-    let worktree = match resolve_worktree_spec(name, &repo, project_root, &repo_config) {
+    let issue_name = match options.issue {
+        Some(issue) => {
+            match render_issue_branch_name(repo_config.issue_branch_template.as_deref(), issue) {
+                Ok(rendered) => Some(rendered),
+                Err(e) => {
+                    eprintln!("{} {}", "Error:".red(), e);
+                    std::process::exit(1);
+                }
+            }
+        }
+        None => None,
+    };
+    // Resolve the copy source up front so a bad name fails before anything is created.
+    let copy_source = match options.copy_from.as_deref() {
+        Some(source) => {
+            if repo_config.copy_files.is_empty() {
+                eprintln!(
+                    "{} --copy-from needs file patterns. Add a \"copyFiles\" list to .groverc.",
+                    "Error:".red()
+                );
+                std::process::exit(1);
+            }
+            match get_worktree(repo, trim_trailing_branch_slashes(source)) {
+                Ok(wt) => Some(wt),
+                Err(e) => {
+                    eprintln!("{} {}", "Error:".red(), e);
+                    std::process::exit(1);
+                }
+            }
+        }
+        None => None,
+    };
+    if let Some(stash) = options.from_stash.as_deref() {
+        if !is_stash_entry(repo, stash) {
+            eprintln!("{} No stash found at '{}'.", "Error:".red(), stash);
+            std::process::exit(1);
+        }
+    }
+    let cwd = env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    let template = match resolve_template_dir(
+        options.template.as_deref(),
+        repo_config.worktree_template_dir.as_deref(),
+        project_root,
+        &cwd,
+    ) {
+        Ok(template) => template,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    let name = name.or(issue_name.as_deref());
+    let mut worktree = match resolve_worktree_spec(name, repo, project_root, &repo_config) {
         Ok(worktree) => worktree,
         Err(e) => {
             eprintln!("{} {}", "Error:".red(), e);
             std::process::exit(1);
         }
     };
-    let worktree_path = match get_worktree_path(&worktree.directory_name, project_root) {
+    // --force exists to reuse a leftover directory, so only pick a new name without it.
+    if options.at.is_none() && !options.force {
+        worktree.directory_name = available_directory_name(project_root, &worktree.directory_name);
+    }
+    let worktree_path = match options.at.as_deref() {
+        Some(at_path) => {
+            prepare_explicit_worktree_path(at_path, &cwd, options.force, !options.dry_run)
+        }
+        None => get_worktree_path(&worktree.directory_name, project_root),
+    };
+    let worktree_path = match worktree_path {
         Ok(p) => p,
         Err(e) => {
             eprintln!("{} {}", "Error:".red(), e);
@@ -56,6 +170,21 @@ pub fn run(name: Option<&str>, track: Option<&str>) {
         }
     };
 
+    let replaces_stale = options.force && worktree_path.exists();
+    if replaces_stale {
+        let cleared = list_worktrees(repo).and_then(|worktrees| match options.dry_run {
+            true => ensure_replaceable(repo, &worktrees, &worktree_path),
+            false => clear_stale_directory(repo, &worktrees, &worktree_path),
+        });
+        if let Err(e) = cleared {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+        if !options.dry_run {
+            output.line(format!("Removed stale directory: {}", worktree_path.display()).dimmed());
+        }
+    }
+
     let worktree_path_str = worktree_path.to_string_lossy().to_string();
     let target_branch = match resolve_target_branch(&worktree.branch_name, track) {
         Ok(branch) => branch,
@@ -65,11 +194,112 @@ pub fn run(name: Option<&str>, track: Option<&str>) {
         }
     };
 
-    // Try to create worktree for existing branch first, fall back to creating new branch
+    if let Some(from) = options.from.as_deref() {
+        if branch_exists(repo, &target_branch) {
+            eprintln!(
+                "{} Branch '{}' already exists; --from only applies to new branches.",
+                "Error:".red(),
+                target_branch
+            );
+            std::process::exit(1);
+        }
+        if let Err(e) = resolve_revision(repo, from) {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    }
+
+    // A branch that only exists on origin is created locally, tracking the remote one.
+    // --from asks for a new branch, so it skips the lookup.
+    // A dry run only looks at remote branches that were already fetched.
+    let remote_track = match (track, options.from.as_deref()) {
+        (None, None) => find_remote_branch(repo, &target_branch, options.fetch && !options.dry_run),
+        _ => None,
+    };
+    if let Some(remote) = remote_track.as_deref() {
+        output.line(format!("Branch '{}' found on remote as {}", target_branch, remote).dimmed());
+    }
+    let track = track.or(remote_track.as_deref());
+    let start_point = match (track, options.from.as_deref()) {
+        (Some(_), _) => None,
+        (None, Some(from)) => Some(from.to_string()),
+        (None, None) => default_start_point(repo),
+    };
+
+    if options.dry_run {
+        let branch_plan = if branch_exists(repo, &target_branch) {
+            format!("{} (existing)", target_branch)
+        } else if let Some(track) = track {
+            format!("{} (new, tracking {})", target_branch, track)
+        } else {
+            let start = start_point.as_deref().unwrap_or("HEAD");
+            format!("{} (new, from {})", target_branch, start)
+        };
+        output.line(format!(
+            "{} {}",
+            "Dry run: would create worktree".bold(),
+            worktree.directory_name.bold()
+        ));
+        output.line(format!("  Path: {}", worktree_path_str));
+        output.line(format!("  Branch: {}", branch_plan));
+        if replaces_stale {
+            output.line("  Replaces the stale directory at that path".dimmed());
+        }
+        if let Some(source) = &copy_source {
+            output.line(format!("  Copies local files from {}", source.branch).dimmed());
+        }
+        if options.lock {
+            output.line(lock_description(options.lock_reason.as_deref()).dimmed());
+        }
+        if options.push {
+            output.line(format!("  Pushes {} to origin", target_branch).dimmed());
+        }
+        if let Some(template) = &template {
+            output.line(format!("  Copies the template at {}", template.display()).dimmed());
+        }
+        if let Some(stash) = options.from_stash.as_deref() {
+            output.line(format!("  Applies {}", stash).dimmed());
+        }
+        let commands = bootstrap_commands(&repo_config, options.no_hooks, output);
+        if !commands.is_empty() {
+            output.line(format!("  Runs {} bootstrap command(s)", commands.len()).dimmed());
+        }
+        output.line("Nothing was created.".dimmed());
+        if options.quiet {
+            let _ = writeln!(output.stdout, "{}", worktree_path.display());
+        }
+        return;
+    }
+
+    // Try to create worktree for existing branch first, fall back to creating new branch.
+    // --lock is part of the same git call, so the worktree is never seen unlocked.
+    let lock = options.lock.then_some(WorktreeLock {
+        reason: options.lock_reason.as_deref(),
+    });
     let mut is_new_branch = false;
-    if let Err(existing_err) = add_worktree(&repo, &worktree_path_str, &target_branch, false, track)
-    {
-        match add_worktree(&repo, &worktree_path_str, &target_branch, true, track) {
+    if let Err(existing_err) = add_worktree_with(
+        repo,
+        &worktree_path_str,
+        &target_branch,
+        false,
+        track,
+        None,
+        lock,
+    ) {
+        let (new_track, new_start) = match start_point.as_deref() {
+            Some(start) => (None, Some(start)),
+            None => (track, None),
+        };
+        let created = add_worktree_with(
+            repo,
+            &worktree_path_str,
+            &target_branch,
+            true,
+            new_track,
+            new_start,
+            lock,
+        );
+        match created {
             Ok(()) => is_new_branch = true,
             Err(new_err) => {
                 let worktree_and_branch = if target_branch == worktree.directory_name {
@@ -78,11 +308,9 @@ pub fn run(name: Option<&str>, track: Option<&str>) {
                     format!("{} (branch: {})", worktree.directory_name, target_branch)
                 };
                 eprintln!(
-                    "{} Failed to create worktree for '{}':\n  As existing branch: {}\n  As new branch: {}",
+                    "{} {}",
                     "Error:".red(),
-                    worktree_and_branch,
-                    existing_err,
-                    new_err
+                    format_add_failure(&worktree_and_branch, &existing_err, &new_err)
                 );
                 std::process::exit(1);
             }
@@ -94,34 +322,187 @@ pub fn run(name: Option<&str>, track: Option<&str>) {
     } else {
         format!("{} (branch: {})", worktree.directory_name, target_branch)
     };
-    if is_new_branch {
-        println!(
-            "{} {}",
-            "✓ Created new branch and worktree:".green(),
-            worktree_and_branch.bold()
-        );
+    let created = if is_new_branch {
+        "✓ Created new branch and worktree:"
     } else {
-        println!(
-            "{} {}",
-            "✓ Created worktree:".green(),
-            worktree_and_branch.bold()
-        );
+        "✓ Created worktree:"
+    };
+    output.line(format!(
+        "{} {}",
+        created.green(),
+        worktree_and_branch.bold()
+    ));
+    output.line(format!("Path: {}", worktree_path_str).dimmed());
+    if options.lock {
+        output.line(lock_description(options.lock_reason.as_deref()).dimmed());
+    }
+
+    // A failed push (e.g. offline) shouldn't undo a worktree that is otherwise fine.
+    if options.push {
+        match push_branch(repo, &worktree_path_str, &target_branch) {
+            Ok(()) => output.line(format!(
+                "{} {}",
+                "✓ Pushed and tracking".green(),
+                format!("origin/{}", target_branch).bold()
+            )),
+            Err(e) => eprintln!(
+                "{} {}. Push it later with 'git push -u origin {}'.",
+                "Warning:".yellow(),
+                e,
+                target_branch
+            ),
+        }
+    }
+
+    if options.open_pr_url {
+        match create_pr_url(repo, &target_branch) {
+            Ok(url) => output.line(format!("{} {}", "Create a pull request:".dimmed(), url)),
+            Err(e) => eprintln!("{} {}", "Warning:".yellow(), e),
+        }
+    }
+
+    copy_into_worktree(
+        &worktree_path,
+        &target_branch,
+        &WorktreeSetup {
+            copy_files: &repo_config.copy_files,
+            copy_source: copy_source.as_ref(),
+            template: template.as_deref(),
+            commands: &[],
+        },
+        output,
+    );
+
+    if let Some(stash) = options.from_stash.as_deref() {
+        match apply_stash(repo, &worktree_path_str, stash, options.pop) {
+            Ok(StashOutcome::Applied) => {
+                let applied = if options.pop { "Popped" } else { "Applied" };
+                output.line(format!("{} {}", format!("✓ {}", applied).green(), stash));
+            }
+            Ok(StashOutcome::Conflict) => {
+                let kept = if options.pop {
+                    "; the stash was kept"
+                } else {
+                    ""
+                };
+                eprintln!(
+                    "{} {} applied with conflicts{}. Resolve them in {}",
+                    "Warning:".yellow(),
+                    stash,
+                    kept,
+                    worktree_path_str
+                );
+            }
+            Err(e) => eprintln!("{} {}", "Warning:".yellow(), e),
+        }
+    }
+
+    let commands = bootstrap_commands(&repo_config, options.no_hooks, output);
+    if !commands.is_empty() {
+        output.line("Running bootstrap commands...".blue());
+        let summary = run_bootstrap_commands(&worktree_path, commands, output);
+        report_bootstrap_summary(&summary, &worktree_path, output);
     }
-    println!("{}", format!("Path: {}", worktree_path_str).dimmed());
 
-    let commands = match repo_config.bootstrap {
-        Some(bootstrap) if !bootstrap.commands.is_empty() => bootstrap.commands,
-        _ => return,
+    if options.quiet {
+        let _ = writeln!(output.stdout, "{}", worktree_path.display());
+    }
+}
+
+/// What goes into a new worktree once it is checked out, besides a stash.
+#[derive(Default)]
+pub struct WorktreeSetup<'a> {
+    /// `copyFiles` patterns, copied from `copy_source`.
+    pub copy_files: &'a [String],
+    /// Worktree to copy local files from; nothing is copied without one.
+    pub copy_source: Option<&'a Worktree>,
+    pub template: Option<&'a Path>,
+    pub commands: &'a [BootstrapCommand],
+}
+
+/// Set up a worktree checked out outside `grove add` (e.g. by `grove sync`)
+/// exactly as `grove add` would: copy local files and the template, then run
+/// the bootstrap commands. Output, including the commands', is captured and
+/// dropped so that worktrees set up in parallel don't interleave it; what
+/// went wrong is returned instead.
+pub fn set_up_worktree(
+    worktree_path: &Path,
+    branch: &str,
+    setup: &WorktreeSetup,
+) -> Result<(), String> {
+    let mut output = AddOutput {
+        stdout: &mut io::sink(),
+        stderr: &mut io::sink(),
+        quiet: false,
+        capture: true,
     };
+    let mut problems = copy_into_worktree(worktree_path, branch, setup, &mut output);
+    let summary = run_bootstrap_commands(worktree_path, setup.commands, &mut output);
+    problems.extend(
+        summary
+            .failed
+            .iter()
+            .map(|(command, reason)| format!("{} ({})", command, reason)),
+    );
+    if problems.is_empty() {
+        Ok(())
+    } else {
+        Err(format!(
+            "created, but setup failed: {}",
+            problems.join(", ")
+        ))
+    }
+}
+
+/// Copy local files and the template into a new worktree. Failures are
+/// warnings, since the worktree itself is fine; they are also returned.
+fn copy_into_worktree(
+    worktree_path: &Path,
+    branch: &str,
+    setup: &WorktreeSetup,
+    output: &mut AddOutput,
+) -> Vec<String> {
+    let mut problems = Vec::new();
+
+    if let Some(source) = setup.copy_source {
+        match copy_local_files(Path::new(&source.path), worktree_path, setup.copy_files) {
+            Ok(copied) => output.line(format!(
+                "{} {}",
+                format!("✓ Copied {} file(s) from", copied.len()).green(),
+                source.branch.bold()
+            )),
+            Err(e) => problems.push(e),
+        }
+    }
+
+    if let Some(template) = setup.template {
+        match copy_template(template, worktree_path, branch) {
+            Ok(copied) => output.line(format!(
+                "{} {}",
+                format!("✓ Copied {} file(s) from template", copied.len()).green(),
+                template.display()
+            )),
+            Err(e) => problems.push(e),
+        }
+    }
+
+    for problem in &problems {
+        let _ = writeln!(output.stderr, "{} {}", "Warning:".yellow(), problem);
+    }
+    problems
+}
 
-    println!("{}", "Running bootstrap commands...".blue());
-    let summary = run_bootstrap_commands(&worktree_path, &commands);
+fn report_bootstrap_summary(
+    summary: &BootstrapSummary,
+    worktree_path: &Path,
+    output: &mut AddOutput,
+) {
     if summary.failed.is_empty() {
-        println!(
+        output.line(format!(
             "{} {}",
             "✓ Bootstrap completed:".green(),
             format!("{}/{} succeeded", summary.succeeded, summary.total).bold()
-        );
+        ));
     } else {
         eprintln!(
             "{} {}",
@@ -146,6 +527,20 @@ pub fn run(name: Option<&str>, track: Option<&str>) {
     }
 }
 
+/// The URL for opening a pull request from `branch` into the default
+/// branch on origin. Only github.com and gitlab.com are recognized.
+fn create_pr_url(repo: &RepoContext, branch: &str) -> Result<String, String> {
+    let url = remote_url(repo).ok_or("No origin remote, so there's no PR URL to show.")?;
+    let remote = parse_remote_url(&url)?;
+    let base = get_default_branch(repo)?;
+    pull_request_url(&remote, &base, branch).ok_or_else(|| {
+        format!(
+            "Don't know how to open a pull request on {}; only github.com and gitlab.com are supported.",
+            remote.host
+        )
+    })
+}
+
 fn resolve_worktree_spec(
     provided_name: Option<&str>,
     repo: &RepoContext,
@@ -154,7 +549,7 @@ fn resolve_worktree_spec(
 ) -> Result<WorktreeSpec, String> {
     if let Some(name) = provided_name {
         return Ok(WorktreeSpec {
-            directory_name: name.to_string(),
+            directory_name: slugify_branch(name),
             branch_name: name.to_string(),
         });
     }
@@ -182,6 +577,41 @@ fn choose_default_worktree_spec(
     )
 }
 
+/// `directory_name`, or the first of `<name>-2`, `<name>-3`, ... that doesn't
+/// exist under the project root yet.
+fn available_directory_name(project_root: &Path, directory_name: &str) -> String {
+    available_directory_name_with(project_root, directory_name, CASE_INSENSITIVE_FS)
+}
+
+fn available_directory_name_with(
+    project_root: &Path,
+    directory_name: &str,
+    case_insensitive: bool,
+) -> String {
+    // Read the entries once so a differently-cased `Feature` also counts as
+    // taken, even when the filesystem reports `feature` as missing.
+    let existing: Vec<String> = fs::read_dir(project_root)
+        .map(|entries| {
+            entries
+                .flatten()
+                .map(|entry| entry.file_name().to_string_lossy().to_string())
+                .collect()
+        })
+        .unwrap_or_default();
+    let is_taken = |name: &str| {
+        project_root.join(name).exists()
+            || (case_insensitive && existing.iter().any(|e| e.eq_ignore_ascii_case(name)))
+    };
+
+    if !is_taken(directory_name) {
+        return directory_name.to_string();
+    }
+    (2..)
+        .map(|n| format!("{}-{}", directory_name, n))
+        .find(|candidate| !is_taken(candidate))
+        .expect("an unused suffix always exists")
+}
+
 fn is_name_available(repo: &RepoContext, project_root: &Path, candidate: &WorktreeSpec) -> bool {
     if branch_exists(repo, &candidate.branch_name) {
         return false;
@@ -214,6 +644,18 @@ fn apply_branch_prefix(
     Ok(format!("{}/{}", prefix, generated_name))
 }
 
+/// Where new branches start: the default branch rather than whatever the bare
+/// clone's HEAD points at, falling back to its remote-tracking branch when
+/// there is no local copy. `None` leaves the choice to git.
+fn default_start_point(repo: &RepoContext) -> Option<String> {
+    let default_branch = get_default_branch(repo).ok()?;
+    if branch_exists(repo, &default_branch) {
+        return Some(default_branch);
+    }
+    let remote = format!("origin/{}", default_branch);
+    resolve_revision(repo, &remote).ok().map(|_| remote)
+}
+
 fn resolve_target_branch(name: &str, track: Option<&str>) -> Result<String, String> {
     match track {
         Some(track_ref) => {
@@ -266,21 +708,362 @@ pub fn get_worktree_path(branch_name: &str, project_root: &Path) -> Result<PathB
     Ok(resolved_path)
 }
 
-fn run_bootstrap_commands(worktree_path: &Path, commands: &[BootstrapCommand]) -> BootstrapSummary {
+/// Resolve an explicit `--at` location, which may live outside the project root.
+/// Relative paths are resolved against `cwd`. Missing parent directories are created.
+fn prepare_explicit_worktree_path(
+    at: &str,
+    cwd: &Path,
+    allow_existing: bool,
+    create_parents: bool,
+) -> Result<PathBuf, String> {
+    let trimmed = at.trim();
+    if trimmed.is_empty() {
+        return Err("Worktree path is required".to_string());
+    }
+
+    let worktree_path = if Path::new(trimmed).is_absolute() {
+        PathBuf::from(trimmed)
+    } else {
+        cwd.join(trimmed)
+    };
+
+    if worktree_path.exists() && !allow_existing {
+        return Err(format!(
+            "Target path '{}' already exists",
+            worktree_path.display()
+        ));
+    }
+
+    if let Some(parent) = worktree_path.parent().filter(|_| create_parents) {
+        fs::create_dir_all(parent).map_err(|e| {
+            format!(
+                "Failed to create parent directory {}: {}",
+                parent.display(),
+                e
+            )
+        })?;
+    }
+
+    Ok(worktree_path)
+}
+
+/// Create a worktree for `branch` in its default directory under the project
+/// root, checking out the branch if it exists locally or on origin and creating
+/// it otherwise. Returns the worktree path and whether the branch is new.
+/// Unlike `grove add`, this doesn't copy files or run bootstrap commands.
+pub fn create_branch_worktree(repo: &RepoContext, branch: &str) -> Result<(PathBuf, bool), String> {
+    let root = project_root(repo);
+    let directory_name = available_directory_name(root, &slugify_branch(branch));
+    let worktree_path = get_worktree_path(&directory_name, root)?;
+    let worktree_path_str = worktree_path.to_string_lossy().to_string();
+
+    let track = find_remote_branch(repo, branch, false);
+    match add_worktree(repo, &worktree_path_str, branch, false, track.as_deref()) {
+        Ok(()) => Ok((worktree_path, false)),
+        Err(existing_err) => add_worktree(repo, &worktree_path_str, branch, true, track.as_deref())
+            .map(|()| (worktree_path, true))
+            .map_err(|new_err| format_add_failure(branch, &existing_err, &new_err)),
+    }
+}
+
+/// Describe both add attempts separately, indenting git's multi-line stderr so
+/// each attempt's output stays visually grouped under its heading.
+fn format_add_failure(worktree_and_branch: &str, existing_err: &str, new_err: &str) -> String {
+    let indent = |text: &str| text.lines().collect::<Vec<_>>().join("\n    ");
+    format!(
+        "Failed to create worktree for '{}':\n  As existing branch:\n    {}\n  As new branch:\n    {}",
+        worktree_and_branch,
+        indent(existing_err),
+        indent(new_err)
+    )
+}
+
+/// Remove a leftover directory so `--force` can reuse its location.
+/// Refuses when the path holds a worktree or the layout itself to avoid losing work.
+fn clear_stale_directory(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    target: &Path,
+) -> Result<(), String> {
+    ensure_replaceable(repo, worktrees, target)?;
+    remove_stale_directory(target)
+}
+
+/// `--force` may only replace directories that are neither, nor contain, the
+/// project root, the bare clone, or a registered worktree.
+fn ensure_replaceable(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    target: &Path,
+) -> Result<(), String> {
+    let target_key = comparable_path(target);
+    let layout = [
+        (project_root(repo), "the project root"),
+        (repo_path(repo), "the bare clone"),
+    ];
+    for (path, what) in layout {
+        let key = comparable_path(path);
+        if key.starts_with(&target_key) {
+            let relation = if key == target_key { "is" } else { "contains" };
+            return Err(format!(
+                "'{}' {} {}; refusing to replace it.",
+                target.display(),
+                relation,
+                what
+            ));
+        }
+    }
+    for wt in worktrees {
+        let key = comparable_path(Path::new(&wt.path));
+        if key == target_key {
+            return Err(format!(
+                "'{}' is a registered worktree; refusing to replace it. Use 'grove remove' first.",
+                target.display()
+            ));
+        }
+        if key.starts_with(&target_key) {
+            return Err(format!(
+                "'{}' contains the registered worktree '{}'; refusing to replace it.",
+                target.display(),
+                wt.path
+            ));
+        }
+    }
+    Ok(())
+}
+
+/// `path` resolved for comparison. canonicalize keeps the caller's casing, so
+/// on case-insensitive filesystems `Feature` and `feature` are folded together.
+fn comparable_path(path: &Path) -> PathBuf {
+    let canonical = fs::canonicalize(path).unwrap_or_else(|_| path.to_path_buf());
+    if CASE_INSENSITIVE_FS {
+        PathBuf::from(canonical.to_string_lossy().to_lowercase())
+    } else {
+        canonical
+    }
+}
+
+fn remove_stale_directory(target: &Path) -> Result<(), String> {
+    let removed = if target.is_dir() {
+        fs::remove_dir_all(target)
+    } else {
+        fs::remove_file(target)
+    };
+    removed.map_err(|e| format!("Failed to remove {}: {}", target.display(), e))
+}
+
+/// Copy files under `source` whose path relative to it matches one of
+/// `patterns` into the same place under `destination`. A matching directory is
+/// copied whole. Files that already exist in `destination` are left alone, and
+/// `.git` and submodule checkouts are never entered. Returns the copied paths.
+fn copy_local_files(
+    source: &Path,
+    destination: &Path,
+    patterns: &[String],
+) -> Result<Vec<PathBuf>, String> {
+    fn walk(
+        dir: &Path,
+        relative: &Path,
+        destination: &Path,
+        patterns: &[String],
+        matched: bool,
+        copied: &mut Vec<PathBuf>,
+    ) -> Result<(), String> {
+        let entries =
+            fs::read_dir(dir).map_err(|e| format!("Failed to read {}: {}", dir.display(), e))?;
+        for entry in entries.flatten() {
+            if entry.file_name() == ".git" {
+                continue;
+            }
+            let path = entry.path();
+            let relative = relative.join(entry.file_name());
+            let relative_str = relative
+                .components()
+                .map(|c| c.as_os_str().to_string_lossy().to_string())
+                .collect::<Vec<_>>()
+                .join("/");
+            let matched = matched
+                || patterns
+                    .iter()
+                    .any(|p| branch_glob_matches(p, &relative_str));
+
+            let Ok(file_type) = entry.file_type() else {
+                continue;
+            };
+            if file_type.is_dir() {
+                if !path.join(".git").exists() {
+                    walk(&path, &relative, destination, patterns, matched, copied)?;
+                }
+            } else if file_type.is_file() && matched {
+                let target = destination.join(&relative);
+                if target.exists() {
+                    continue;
+                }
+                if let Some(parent) = target.parent() {
+                    fs::create_dir_all(parent)
+                        .map_err(|e| format!("Failed to create {}: {}", parent.display(), e))?;
+                }
+                fs::copy(&path, &target)
+                    .map_err(|e| format!("Failed to copy {}: {}", relative_str, e))?;
+                copied.push(relative);
+            }
+        }
+        Ok(())
+    }
+
+    let mut copied = Vec::new();
+    walk(
+        source,
+        Path::new(""),
+        destination,
+        patterns,
+        false,
+        &mut copied,
+    )?;
+    Ok(copied)
+}
+
+/// The line shown for a worktree created with `--lock`.
+fn lock_description(reason: Option<&str>) -> String {
+    match reason {
+        Some(reason) => format!("Locked: {}", reason),
+        None => "Locked".to_string(),
+    }
+}
+
+/// The bootstrap commands to run after creating a worktree; none when `skip`
+/// is set by `--no-hooks`.
+fn bootstrap_commands<'a>(
+    repo_config: &'a RepoConfig,
+    skip: bool,
+    output: &mut AddOutput,
+) -> &'a [BootstrapCommand] {
+    match &repo_config.bootstrap {
+        Some(bootstrap) if !skip => &bootstrap.commands,
+        Some(bootstrap) => {
+            if !bootstrap.commands.is_empty() {
+                output.line(
+                    format!(
+                        "Skipped {} bootstrap command(s) (--no-hooks).",
+                        bootstrap.commands.len()
+                    )
+                    .dimmed(),
+                );
+            }
+            &[]
+        }
+        None => &[],
+    }
+}
+
+/// The template directory for a new worktree: `--template` (relative to the
+/// current directory) wins over `worktreeTemplateDir` (relative to the
+/// project root).
+pub fn resolve_template_dir(
+    flag: Option<&str>,
+    configured: Option<&str>,
+    project_root: &Path,
+    cwd: &Path,
+) -> Result<Option<PathBuf>, String> {
+    let template = match (flag, configured) {
+        (Some(flag), _) => cwd.join(flag),
+        (None, Some(configured)) => project_root.join(configured),
+        (None, None) => return Ok(None),
+    };
+    if !template.is_dir() {
+        return Err(format!(
+            "Template directory not found: {}",
+            template.display()
+        ));
+    }
+    Ok(Some(template))
+}
+
+/// Copy everything under `template` into `destination`, keeping its layout
+/// and file modes and never overwriting existing files. A `.tmpl` file is
+/// written without that extension and with `{branch}` replaced by `branch`.
+fn copy_template(
+    template: &Path,
+    destination: &Path,
+    branch: &str,
+) -> Result<Vec<PathBuf>, String> {
+    fn walk(
+        dir: &Path,
+        relative: &Path,
+        destination: &Path,
+        branch: &str,
+        copied: &mut Vec<PathBuf>,
+    ) -> Result<(), String> {
+        let entries =
+            fs::read_dir(dir).map_err(|e| format!("Failed to read {}: {}", dir.display(), e))?;
+        for entry in entries.flatten() {
+            let path = entry.path();
+            let Ok(file_type) = entry.file_type() else {
+                continue;
+            };
+            if file_type.is_dir() {
+                walk(
+                    &path,
+                    &relative.join(entry.file_name()),
+                    destination,
+                    branch,
+                    copied,
+                )?;
+                continue;
+            }
+            if !file_type.is_file() {
+                continue;
+            }
+
+            let file_name = entry.file_name().to_string_lossy().to_string();
+            let rendered_name = file_name.strip_suffix(".tmpl").filter(|n| !n.is_empty());
+            let relative = relative.join(rendered_name.unwrap_or(&file_name));
+            let target = destination.join(&relative);
+            if target.exists() {
+                continue;
+            }
+            if let Some(parent) = target.parent() {
+                fs::create_dir_all(parent)
+                    .map_err(|e| format!("Failed to create {}: {}", parent.display(), e))?;
+            }
+            let copy_error =
+                |e: std::io::Error| format!("Failed to copy {}: {}", path.display(), e);
+            if rendered_name.is_some() {
+                let content = fs::read_to_string(&path).map_err(copy_error)?;
+                fs::write(&target, content.replace("{branch}", branch)).map_err(copy_error)?;
+                let permissions = fs::metadata(&path).map_err(copy_error)?.permissions();
+                fs::set_permissions(&target, permissions).map_err(copy_error)?;
+            } else {
+                fs::copy(&path, &target).map_err(copy_error)?;
+            }
+            copied.push(relative);
+        }
+        Ok(())
+    }
+
+    let mut copied = Vec::new();
+    walk(template, Path::new(""), destination, branch, &mut copied)?;
+    Ok(copied)
+}
+
+fn run_bootstrap_commands(
+    worktree_path: &Path,
+    commands: &[BootstrapCommand],
+    output: &mut AddOutput,
+) -> BootstrapSummary {
     let mut succeeded = 0;
     let mut failed = Vec::new();
 
     for (idx, command) in commands.iter().enumerate() {
         let command_display = format_bootstrap_command(command);
-        println!(
-            "{}",
+        output.line(
             format!(
                 "[bootstrap {}/{}] {}",
                 idx + 1,
                 commands.len(),
                 command_display
             )
-            .dimmed()
+            .dimmed(),
         );
 
         if command.program.trim().is_empty() {
@@ -291,12 +1074,25 @@ fn run_bootstrap_commands(worktree_path: &Path, commands: &[BootstrapCommand]) -
             continue;
         }
 
-        let result = Command::new(&command.program)
-            .args(&command.args)
-            .current_dir(worktree_path)
-            .stdout(Stdio::inherit())
-            .stderr(Stdio::inherit())
-            .status();
+        let mut child = Command::new(&command.program);
+        child.args(&command.args).current_dir(worktree_path);
+        let result = if output.capture {
+            child.output().map(|captured| {
+                let _ = output.status().write_all(&captured.stdout);
+                let _ = output.stderr.write_all(&captured.stderr);
+                captured.status
+            })
+        } else {
+            child
+                // Keep stdout for the worktree path under --quiet.
+                .stdout(if output.quiet {
+                    Stdio::from(io::stderr())
+                } else {
+                    Stdio::inherit()
+                })
+                .stderr(Stdio::inherit())
+                .status()
+        };
 
         match result {
             Ok(status) if status.success() => {
@@ -322,7 +1118,24 @@ fn run_bootstrap_commands(worktree_path: &Path, commands: &[BootstrapCommand]) -
     }
 }
 
-fn format_bootstrap_command(command: &BootstrapCommand) -> String {
+/// Run bootstrap commands in an existing worktree with the same output as
+/// `grove add`. False when any command failed.
+pub fn rerun_bootstrap(worktree_path: &Path, commands: &[BootstrapCommand]) -> bool {
+    let mut stdout = io::stdout();
+    let mut stderr = io::stderr();
+    let mut output = AddOutput {
+        stdout: &mut stdout,
+        stderr: &mut stderr,
+        quiet: false,
+        capture: false,
+    };
+    output.line("Running bootstrap commands...".blue());
+    let summary = run_bootstrap_commands(worktree_path, commands, &mut output);
+    report_bootstrap_summary(&summary, worktree_path, &mut output);
+    summary.failed.is_empty()
+}
+
+pub fn format_bootstrap_command(command: &BootstrapCommand) -> String {
     if command.args.is_empty() {
         command.program.clone()
     } else {
@@ -333,10 +1146,9 @@ fn format_bootstrap_command(command: &BootstrapCommand) -> String {
 #[cfg(test)]
 mod tests {
     use super::*;
-    use crate::utils::make_temp_dir;
+    use crate::git::{create_test_repo, repo_path, run_test_git, upstream_branch};
+    use crate::utils::{make_temp_dir, read_repo_config, RepoBootstrapConfig};
     use regex::Regex;
-    use std::env;
-    use std::fs;
 
     // --- getWorktreePath security tests ---
 
@@ -416,16 +1228,617 @@ mod tests {
         assert!(result.is_ok());
     }
 
+    #[test]
+    fn prepare_explicit_worktree_path_resolves_relative_and_creates_parents() {
+        let cwd = make_temp_dir("add-at-relative");
+        let path = prepare_explicit_worktree_path("disks/fast/feature", &cwd, false, true).unwrap();
+        assert_eq!(path, cwd.join("disks/fast/feature"));
+        assert!(cwd.join("disks/fast").is_dir());
+        assert!(!path.exists());
+        let _ = fs::remove_dir_all(cwd);
+    }
+
+    #[test]
+    fn prepare_explicit_worktree_path_rejects_existing_target() {
+        let cwd = make_temp_dir("add-at-existing");
+        fs::create_dir_all(cwd.join("taken")).unwrap();
+        let err = prepare_explicit_worktree_path("taken", &cwd, false, true).unwrap_err();
+        assert!(prepare_explicit_worktree_path("taken", &cwd, true, true).is_ok());
+        assert!(err.contains("already exists"));
+        let _ = fs::remove_dir_all(cwd);
+    }
+
+    #[test]
+    fn worktree_created_outside_project_root_is_listed() {
+        let repo = create_test_repo("add-at-external");
+        let external = make_temp_dir("add-at-external-target");
+        let target = prepare_explicit_worktree_path(
+            &external.join("elsewhere/feature-at").to_string_lossy(),
+            &external,
+            false,
+            true,
+        )
+        .unwrap();
+
+        add_worktree(
+            &repo.context,
+            &target.to_string_lossy(),
+            "feature-at",
+            true,
+            None,
+        )
+        .unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert!(worktrees
+            .iter()
+            .any(|wt| wt.branch == "feature-at" && Path::new(&wt.path) == target));
+        let _ = fs::remove_dir_all(external);
+    }
+
+    #[test]
+    fn nested_branch_gets_flat_directory_with_collision_suffix() {
+        let repo = create_test_repo("add-slug-collision");
+        let root = project_root(&repo.context);
+        let config = RepoConfig::default();
+
+        let spec = resolve_worktree_spec(
+            Some("feature/JIRA-123/some-thing"),
+            &repo.context,
+            root,
+            &config,
+        )
+        .unwrap();
+        assert_eq!(spec.branch_name, "feature/JIRA-123/some-thing");
+        assert_eq!(spec.directory_name, "feature-JIRA-123-some-thing");
+        assert_eq!(
+            available_directory_name(root, &spec.directory_name),
+            "feature-JIRA-123-some-thing"
+        );
+
+        fs::create_dir_all(root.join("feature-JIRA-123-some-thing")).unwrap();
+        fs::create_dir_all(root.join("feature-JIRA-123-some-thing-2")).unwrap();
+        assert_eq!(
+            available_directory_name(root, &spec.directory_name),
+            "feature-JIRA-123-some-thing-3"
+        );
+    }
+
+    #[test]
+    fn create_branch_worktree_uses_existing_or_new_branch() {
+        let repo = create_test_repo("add-create-branch-worktree");
+        run_test_git(
+            repo_path(&repo.context),
+            &["branch", "feature/existing", "main"],
+        );
+
+        let (path, is_new) = create_branch_worktree(&repo.context, "feature/existing").unwrap();
+        assert!(!is_new);
+        assert_eq!(
+            path,
+            fs::canonicalize(project_root(&repo.context))
+                .unwrap()
+                .join("feature-existing")
+        );
+
+        let (path, is_new) = create_branch_worktree(&repo.context, "feature/new").unwrap();
+        assert!(is_new);
+        assert!(path.ends_with("feature-new"));
+        assert!(branch_exists(&repo.context, "feature/new"));
+    }
+
+    #[test]
+    fn case_insensitive_collision_detects_differently_cased_directory() {
+        let root = make_temp_dir("add-case-collision");
+        fs::create_dir_all(root.join("Feature")).unwrap();
+
+        assert_eq!(
+            available_directory_name_with(&root, "feature", true),
+            "feature-2"
+        );
+        if !root.join("feature").exists() {
+            assert_eq!(
+                available_directory_name_with(&root, "feature", false),
+                "feature"
+            );
+        }
+        let _ = fs::remove_dir_all(root);
+    }
+
+    #[test]
+    fn issue_worktree_follows_branch_template() {
+        let repo = create_test_repo("add-issue");
+        let root = project_root(&repo.context);
+        fs::write(
+            root.join(".groverc"),
+            r#"{ "issueBranchTemplate": "gh/{number}-fix" }"#,
+        )
+        .unwrap();
+
+        let config = read_repo_config(root).unwrap();
+        let name = render_issue_branch_name(config.issue_branch_template.as_deref(), 42).unwrap();
+        let spec = resolve_worktree_spec(Some(&name), &repo.context, root, &config).unwrap();
+        assert_eq!(spec.branch_name, "gh/42-fix");
+        assert_eq!(spec.directory_name, "gh-42-fix");
+
+        let path = get_worktree_path(&spec.directory_name, root).unwrap();
+        add_worktree(
+            &repo.context,
+            &path.to_string_lossy(),
+            &spec.branch_name,
+            true,
+            None,
+        )
+        .unwrap();
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert!(worktrees
+            .iter()
+            .any(|wt| wt.branch == "gh/42-fix" && wt.path.ends_with("gh-42-fix")));
+    }
+
+    #[test]
+    fn remote_only_branch_is_fetched_and_tracked() {
+        let repo = create_test_repo("add-remote-branch");
+        run_test_git(&repo.dir.join("origin"), &["branch", "feature-remote"]);
+        assert!(!branch_exists(&repo.context, "feature-remote"));
+
+        assert!(find_remote_branch(&repo.context, "feature-remote", false).is_none());
+        let remote = find_remote_branch(&repo.context, "feature-remote", true).unwrap();
+        assert_eq!(remote, "origin/feature-remote");
+        assert!(find_remote_branch(&repo.context, "feature-missing", true).is_none());
+
+        let path = project_root(&repo.context).join("feature-remote");
+        add_worktree(
+            &repo.context,
+            &path.to_string_lossy(),
+            "feature-remote",
+            true,
+            Some(&remote),
+        )
+        .unwrap();
+
+        assert!(branch_exists(&repo.context, "feature-remote"));
+        assert_eq!(
+            upstream_branch(&repo.context, "feature-remote").as_deref(),
+            Some("origin/feature-remote")
+        );
+        // The branch is local now, so there is nothing left to fetch.
+        assert!(find_remote_branch(&repo.context, "feature-remote", true).is_none());
+    }
+
+    #[test]
+    fn add_failure_lists_each_attempt_distinctly() {
+        let message = format_add_failure(
+            "feature",
+            "Failed to add worktree: fatal: invalid reference: feature",
+            "Failed to add worktree: fatal: 'C:/x' could not be created\nhint: enable core.longpaths",
+        );
+
+        assert_eq!(
+            message,
+            "Failed to create worktree for 'feature':\n  As existing branch:\n    Failed to add worktree: fatal: invalid reference: feature\n  As new branch:\n    Failed to add worktree: fatal: 'C:/x' could not be created\n    hint: enable core.longpaths"
+        );
+    }
+
+    #[test]
+    fn clear_stale_directory_removes_unregistered_directory() {
+        let repo = create_test_repo("add-force-stale");
+        let stale = project_root(&repo.context).join("feature-stale");
+        fs::create_dir_all(&stale).unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        clear_stale_directory(&repo.context, &worktrees, &stale).unwrap();
+        assert!(!stale.exists());
+    }
+
+    #[test]
+    fn clear_stale_directory_refuses_registered_worktree() {
+        let repo = create_test_repo("add-force-registered");
+        let registered = repo.add_worktree("feature-live");
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let err = clear_stale_directory(&repo.context, &worktrees, &registered).unwrap_err();
+        assert!(err.contains("registered worktree"));
+        assert!(registered.join(".git").exists());
+    }
+
+    #[test]
+    fn clear_stale_directory_refuses_the_bare_clone() {
+        let repo = create_test_repo("add-force-bare");
+        let bare = repo_path(&repo.context).to_path_buf();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let err = clear_stale_directory(&repo.context, &worktrees, &bare).unwrap_err();
+        assert!(err.contains("is the bare clone"), "{}", err);
+        assert!(bare.join("HEAD").exists());
+    }
+
+    #[test]
+    fn clear_stale_directory_refuses_the_project_root_and_its_ancestors() {
+        let repo = create_test_repo("add-force-root");
+        let root = project_root(&repo.context).to_path_buf();
+        let worktrees = list_worktrees(&repo.context).unwrap();
+
+        let err = clear_stale_directory(&repo.context, &worktrees, &root).unwrap_err();
+        assert!(err.contains("is the project root"), "{}", err);
+        let err = clear_stale_directory(&repo.context, &worktrees, &repo.dir).unwrap_err();
+        assert!(err.contains("contains the project root"), "{}", err);
+        assert!(repo_path(&repo.context).join("HEAD").exists());
+    }
+
+    #[test]
+    fn clear_stale_directory_refuses_a_directory_holding_a_worktree() {
+        let repo = create_test_repo("add-force-parent");
+        let external = repo.dir.join("disks").join("feature-at");
+        add_worktree(
+            &repo.context,
+            &external.to_string_lossy(),
+            "feature-at",
+            true,
+            None,
+        )
+        .unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let err =
+            clear_stale_directory(&repo.context, &worktrees, &repo.dir.join("disks")).unwrap_err();
+        assert!(err.contains("contains the registered worktree"), "{}", err);
+        assert!(external.join(".git").exists());
+    }
+
+    #[test]
+    fn copy_from_named_worktree_seeds_local_files() {
+        let repo = create_test_repo("add-copy-from");
+        let source = repo.add_worktree("main");
+        fs::write(source.join(".env"), "SECRET=1").unwrap();
+        fs::create_dir_all(source.join("config")).unwrap();
+        fs::write(source.join("config").join("local.json"), "{}").unwrap();
+        fs::write(source.join("notes.txt"), "not copied").unwrap();
+        let root = project_root(&repo.context);
+        fs::write(
+            root.join(".groverc"),
+            r#"{ "copyFiles": [".env", "config/*.json"] }"#,
+        )
+        .unwrap();
+        let config = read_repo_config(root).unwrap();
+
+        let destination = repo.add_worktree("feature-copy");
+        fs::write(destination.join("README.md"), "kept").unwrap();
+        let source_wt = get_worktree(&repo.context, "main").unwrap();
+        let mut copied =
+            copy_local_files(Path::new(&source_wt.path), &destination, &config.copy_files).unwrap();
+        copied.sort();
+
+        assert_eq!(
+            copied,
+            vec![
+                PathBuf::from(".env"),
+                Path::new("config").join("local.json")
+            ]
+        );
+        assert_eq!(
+            fs::read_to_string(destination.join(".env")).unwrap(),
+            "SECRET=1"
+        );
+        assert!(!destination.join("notes.txt").exists());
+        assert_eq!(
+            fs::read_to_string(destination.join("README.md")).unwrap(),
+            "kept"
+        );
+    }
+
+    /// Buffers standing in for stdout and stderr.
+    #[derive(Default)]
+    struct Captured {
+        stdout: Vec<u8>,
+        stderr: Vec<u8>,
+    }
+
+    impl Captured {
+        fn output(&mut self, quiet: bool) -> AddOutput<'_> {
+            AddOutput {
+                stdout: &mut self.stdout,
+                stderr: &mut self.stderr,
+                quiet,
+                capture: false,
+            }
+        }
+    }
+
+    fn add_options(name: &str, quiet: bool) -> AddOptions {
+        AddOptions {
+            name: Some(name.to_string()),
+            track: None,
+            at: None,
+            force: false,
+            issue: None,
+            fetch: false,
+            copy_from: None,
+            template: None,
+            no_hooks: false,
+            open_pr_url: false,
+            quiet,
+            from: None,
+            dry_run: false,
+            from_stash: None,
+            pop: false,
+            push: false,
+            lock: false,
+            lock_reason: None,
+        }
+    }
+
+    #[test]
+    fn new_branches_start_at_the_default_branch_unless_from_is_given() {
+        let repo = create_test_repo("add-default-start");
+        let bare = repo_path(&repo.context).to_path_buf();
+        // Point the bare clone's HEAD at a branch that has moved past main.
+        let other = repo.add_worktree("other");
+        run_test_git(&other, &["commit", "-q", "--allow-empty", "-m", "other"]);
+        run_test_git(&bare, &["symbolic-ref", "HEAD", "refs/heads/other"]);
+        run_test_git(&bare, &["fetch", "-q", "origin"]);
+        run_test_git(&bare, &["remote", "set-head", "origin", "main"]);
+        let tip = |rev: &str| run_test_git(&bare, &["rev-parse", rev]);
+
+        add(
+            &repo.context,
+            &add_options("feature-x", true),
+            &mut Captured::default().output(true),
+        );
+        assert_eq!(tip("feature-x"), tip("main"));
+        assert_eq!(upstream_branch(&repo.context, "feature-x"), None);
+
+        let mut options = add_options("feature-y", true);
+        options.from = Some("other".to_string());
+        add(
+            &repo.context,
+            &options,
+            &mut Captured::default().output(true),
+        );
+        assert_eq!(tip("feature-y"), tip("other"));
+    }
+
+    #[test]
+    fn quiet_prints_only_the_worktree_path_on_stdout() {
+        let repo = create_test_repo("add-quiet");
+
+        let mut captured = Captured::default();
+        add(
+            &repo.context,
+            &add_options("feature-x", true),
+            &mut captured.output(true),
+        );
+        let expected = project_root(&repo.context).join("feature-x");
+        assert_eq!(
+            String::from_utf8(captured.stdout).unwrap(),
+            format!("{}\n", expected.display())
+        );
+        assert!(String::from_utf8(captured.stderr)
+            .unwrap()
+            .contains("Created new branch and worktree"));
+
+        let mut captured = Captured::default();
+        add(
+            &repo.context,
+            &add_options("feature-y", false),
+            &mut captured.output(false),
+        );
+        let stdout = String::from_utf8(captured.stdout).unwrap();
+        assert!(stdout.contains("Created new branch and worktree"));
+        assert!(captured.stderr.is_empty());
+    }
+
+    #[test]
+    fn dry_run_reports_the_plan_without_creating_anything() {
+        let repo = create_test_repo("add-dry-run");
+        let options = AddOptions {
+            dry_run: true,
+            ..add_options("feature-x", false)
+        };
+
+        let mut captured = Captured::default();
+        add(&repo.context, &options, &mut captured.output(false));
+        let stdout = String::from_utf8(captured.stdout).unwrap();
+        let expected = project_root(&repo.context).join("feature-x");
+        assert!(stdout.contains(&format!("Path: {}", expected.display())));
+        assert!(stdout.contains("Branch: feature-x (new, from main)"));
+        assert!(!expected.exists());
+        assert!(!branch_exists(&repo.context, "feature-x"));
+
+        let elsewhere = repo.dir.join("elsewhere").join("feature-x");
+        let options = AddOptions {
+            at: Some(elsewhere.to_string_lossy().into_owned()),
+            quiet: true,
+            ..options
+        };
+        let mut captured = Captured::default();
+        add(&repo.context, &options, &mut captured.output(true));
+        assert_eq!(
+            String::from_utf8(captured.stdout).unwrap(),
+            format!("{}\n", elsewhere.display())
+        );
+        assert!(!elsewhere.parent().unwrap().exists());
+    }
+
+    #[test]
+    fn from_stash_applies_the_stash_in_the_new_worktree() {
+        let repo = create_test_repo("add-from-stash");
+        let source = repo.add_worktree("source");
+        fs::write(source.join("README.md"), "# In progress\n").unwrap();
+        run_test_git(&source, &["stash", "-q"]);
+
+        let options = AddOptions {
+            from_stash: Some("stash@{0}".to_string()),
+            pop: true,
+            ..add_options("feature-x", false)
+        };
+        add(
+            &repo.context,
+            &options,
+            &mut Captured::default().output(false),
+        );
+
+        let worktree = project_root(&repo.context).join("feature-x");
+        assert_eq!(
+            fs::read_to_string(worktree.join("README.md")).unwrap(),
+            "# In progress\n"
+        );
+        assert_eq!(
+            fs::read_to_string(source.join("README.md")).unwrap(),
+            "# Test\n"
+        );
+        assert!(run_test_git(&source, &["stash", "list"]).is_empty());
+    }
+
+    #[test]
+    fn push_pushes_the_new_branch_and_tracks_it() {
+        let repo = create_test_repo("add-push");
+        let options = AddOptions {
+            push: true,
+            ..add_options("feature-x", false)
+        };
+        add(
+            &repo.context,
+            &options,
+            &mut Captured::default().output(false),
+        );
+
+        let origin = repo.dir.join("origin");
+        assert!(run_test_git(&origin, &["branch", "--list", "feature-x"]).contains("feature-x"));
+        let worktree = get_worktree(&repo.context, "feature-x").unwrap();
+        let upstream = run_test_git(
+            Path::new(&worktree.path),
+            &["rev-parse", "--abbrev-ref", "@{upstream}"],
+        );
+        assert_eq!(upstream.trim(), "origin/feature-x");
+    }
+
+    #[test]
+    fn failed_push_still_creates_the_worktree() {
+        let repo = create_test_repo("add-push-offline");
+        let bare = repo_path(&repo.context);
+        let missing = repo.dir.join("missing-remote");
+        run_test_git(
+            bare,
+            &["remote", "set-url", "origin", &missing.to_string_lossy()],
+        );
+        let options = AddOptions {
+            push: true,
+            ..add_options("feature-x", false)
+        };
+        add(
+            &repo.context,
+            &options,
+            &mut Captured::default().output(false),
+        );
+
+        let worktree = get_worktree(&repo.context, "feature-x").unwrap();
+        assert!(Path::new(&worktree.path).exists());
+        assert_eq!(upstream_branch(&repo.context, "feature-x"), None);
+    }
+
+    #[test]
+    fn lock_locks_the_new_worktree_with_its_reason() {
+        let repo = create_test_repo("add-lock");
+        let options = AddOptions {
+            lock: true,
+            lock_reason: Some("CI runner".to_string()),
+            ..add_options("feature-x", false)
+        };
+        let mut captured = Captured::default();
+        add(&repo.context, &options, &mut captured.output(false));
+        assert!(String::from_utf8(captured.stdout)
+            .unwrap()
+            .contains("Locked: CI runner"));
+
+        let worktree = get_worktree(&repo.context, "feature-x").unwrap();
+        assert!(worktree.is_locked);
+        assert_eq!(worktree.lock_reason.as_deref(), Some("CI runner"));
+    }
+
     #[test]
     fn bootstrap_no_commands_is_noop() {
         let worktree_dir = make_temp_dir("bootstrap-empty");
-        let summary = run_bootstrap_commands(&worktree_dir, &[]);
+        let summary =
+            run_bootstrap_commands(&worktree_dir, &[], &mut Captured::default().output(false));
         assert_eq!(summary.total, 0);
         assert_eq!(summary.succeeded, 0);
         assert_eq!(summary.failed.len(), 0);
         let _ = fs::remove_dir_all(worktree_dir);
     }
 
+    #[cfg(unix)]
+    #[test]
+    fn template_is_copied_with_branch_substituted_in_tmpl_files() {
+        use std::os::unix::fs::PermissionsExt;
+
+        let repo = create_test_repo("add-template");
+        let template = repo.dir.join("template");
+        fs::create_dir_all(template.join(".vscode")).unwrap();
+        fs::write(template.join(".vscode").join("settings.json"), "{}\n").unwrap();
+        fs::write(template.join("NOTES.md.tmpl"), "# Notes for {branch}\n").unwrap();
+        fs::write(template.join("README.md"), "template readme\n").unwrap();
+        fs::write(template.join("run.sh"), "#!/bin/sh\n").unwrap();
+        fs::set_permissions(template.join("run.sh"), fs::Permissions::from_mode(0o755)).unwrap();
+
+        let options = AddOptions {
+            template: Some(template.to_string_lossy().into_owned()),
+            ..add_options("feature/x", false)
+        };
+        add(
+            &repo.context,
+            &options,
+            &mut Captured::default().output(false),
+        );
+
+        let worktree = project_root(&repo.context).join("feature-x");
+        assert_eq!(
+            fs::read_to_string(worktree.join("NOTES.md")).unwrap(),
+            "# Notes for feature/x\n"
+        );
+        assert!(!worktree.join("NOTES.md.tmpl").exists());
+        assert!(worktree.join(".vscode").join("settings.json").exists());
+        // Files the branch already has are left alone.
+        assert_eq!(
+            fs::read_to_string(worktree.join("README.md")).unwrap(),
+            "# Test\n"
+        );
+        let mode = fs::metadata(worktree.join("run.sh"))
+            .unwrap()
+            .permissions()
+            .mode();
+        assert_eq!(mode & 0o777, 0o755);
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn no_hooks_skips_bootstrap_commands() {
+        let worktree_dir = make_temp_dir("bootstrap-no-hooks");
+        let config = RepoConfig {
+            bootstrap: Some(RepoBootstrapConfig {
+                commands: vec![BootstrapCommand {
+                    program: "sh".to_string(),
+                    args: vec!["-c".to_string(), "touch sentinel".to_string()],
+                }],
+                ..RepoBootstrapConfig::default()
+            }),
+            ..RepoConfig::default()
+        };
+
+        let mut captured = Captured::default();
+        let mut output = captured.output(false);
+        let commands = bootstrap_commands(&config, true, &mut output);
+        let summary = run_bootstrap_commands(&worktree_dir, commands, &mut output);
+        assert_eq!(summary.total, 0);
+        assert!(!worktree_dir.join("sentinel").exists());
+
+        let commands = bootstrap_commands(&config, false, &mut output);
+        let summary = run_bootstrap_commands(&worktree_dir, commands, &mut output);
+        assert_eq!(summary.succeeded, 1);
+        assert!(worktree_dir.join("sentinel").exists());
+        let _ = fs::remove_dir_all(worktree_dir);
+    }
+
     #[test]
     fn bootstrap_continues_after_failure() {
         let worktree_dir = make_temp_dir("bootstrap-continue");
@@ -444,7 +1857,11 @@ mod tests {
             },
         ];
 
-        let summary = run_bootstrap_commands(&worktree_dir, &commands);
+        let summary = run_bootstrap_commands(
+            &worktree_dir,
+            &commands,
+            &mut Captured::default().output(false),
+        );
         assert_eq!(summary.total, 3);
         assert_eq!(summary.succeeded, 2);
         assert_eq!(summary.failed.len(), 1);
@@ -462,7 +1879,11 @@ mod tests {
             args: vec!["--version".to_string()],
         }];
 
-        let summary = run_bootstrap_commands(&worktree_dir, &commands);
+        let summary = run_bootstrap_commands(
+            &worktree_dir,
+            &commands,
+            &mut Captured::default().output(false),
+        );
         assert_eq!(summary.total, 1);
         assert_eq!(summary.succeeded, 0);
         assert_eq!(summary.failed.len(), 1);
@@ -0,0 +1,430 @@
+use colored::Colorize;
+use std::env;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+use crate::git::{discover_repo, project_root};
+use crate::utils::{
+    get_config_path, sanitize_branch_prefix, validate_issue_branch_template, write_file_atomic,
+    GroveConfig, RepoConfig,
+};
+
+pub fn edit() {
+    let path = get_config_path();
+    let editor = resolve_editor();
+
+    match edit_config_file(&path, &editor) {
+        Ok(()) => {
+            println!("{}", format!("✓ Saved config: {}", path.display()).green());
+        }
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    }
+}
+
+pub fn validate() {
+    let mut files = vec![(get_config_path(), validate_grove_config as ConfigValidator)];
+    if let Ok(repo) = discover_repo() {
+        files.push((project_root(&repo).join(".groverc"), validate_repo_config));
+    }
+
+    let mut failed = false;
+    for (path, validator) in files {
+        let content = match fs::read_to_string(&path) {
+            Ok(content) => content,
+            Err(e) if e.kind() == std::io::ErrorKind::NotFound => {
+                println!(
+                    "{}",
+                    format!("- {} not found; using defaults", path.display()).dimmed()
+                );
+                continue;
+            }
+            Err(e) => {
+                eprintln!(
+                    "{} Failed to read {}: {}",
+                    "Error:".red(),
+                    path.display(),
+                    e
+                );
+                failed = true;
+                continue;
+            }
+        };
+
+        let mut problems = validator(&content);
+        problems.sort_by_key(|problem| problem.line);
+        if problems.is_empty() {
+            println!("{}", format!("✓ {} is valid", path.display()).green());
+            continue;
+        }
+        failed = true;
+        for problem in &problems {
+            let location = match problem.line {
+                Some(line) => format!("{}:{}", path.display(), line),
+                None => path.display().to_string(),
+            };
+            eprintln!("{} {}: {}", "✗".red(), location, problem.message);
+        }
+    }
+
+    if failed {
+        std::process::exit(1);
+    }
+}
+
+/// Something wrong with a config file, with the 1-based line it was found on
+/// when that can be determined.
+#[derive(Debug, PartialEq, Eq)]
+struct ConfigProblem {
+    line: Option<usize>,
+    message: String,
+}
+
+type ConfigValidator = fn(&str) -> Vec<ConfigProblem>;
+
+const GROVE_CONFIG_KEYS: &[&str] = &[
+    "shellTipShown",
+    "branchPrefix",
+    "issueBranchTemplate",
+    "copyFiles",
+    "worktreeTemplateDir",
+    "listDirtyCheck",
+];
+const REPO_CONFIG_KEYS: &[&str] = &[
+    "bootstrap",
+    "branchPrefix",
+    "issueBranchTemplate",
+    "copyFiles",
+    "worktreeTemplateDir",
+    "ideFiles",
+];
+const BOOTSTRAP_KEYS: &[&str] = &["commands", "expandEnv"];
+const BOOTSTRAP_COMMAND_KEYS: &[&str] = &["program", "args"];
+
+fn validate_grove_config(content: &str) -> Vec<ConfigProblem> {
+    let value = match parse_config_json(content) {
+        Ok(value) => value,
+        Err(problem) => return vec![problem],
+    };
+
+    let mut problems = Vec::new();
+    check_unknown_keys(content, &value, GROVE_CONFIG_KEYS, "", &mut problems);
+    match serde_json::from_str::<GroveConfig>(content) {
+        Ok(config) => check_naming(
+            content,
+            config.branch_prefix.as_deref(),
+            config.issue_branch_template.as_deref(),
+            &mut problems,
+        ),
+        Err(e) => problems.push(serde_problem(&e)),
+    }
+    problems
+}
+
+fn validate_repo_config(content: &str) -> Vec<ConfigProblem> {
+    let value = match parse_config_json(content) {
+        Ok(value) => value,
+        Err(problem) => return vec![problem],
+    };
+
+    let mut problems = Vec::new();
+    check_unknown_keys(content, &value, REPO_CONFIG_KEYS, "", &mut problems);
+    if let Some(bootstrap) = value.get("bootstrap") {
+        check_unknown_keys(
+            content,
+            bootstrap,
+            BOOTSTRAP_KEYS,
+            "bootstrap.",
+            &mut problems,
+        );
+        if let Some(commands) = bootstrap.get("commands").and_then(|c| c.as_array()) {
+            for (index, command) in commands.iter().enumerate() {
+                let prefix = format!("bootstrap.commands[{}].", index);
+                check_unknown_keys(
+                    content,
+                    command,
+                    BOOTSTRAP_COMMAND_KEYS,
+                    &prefix,
+                    &mut problems,
+                );
+            }
+        }
+    }
+
+    let config: RepoConfig = match serde_json::from_str(content) {
+        Ok(config) => config,
+        Err(e) => {
+            problems.push(serde_problem(&e));
+            return problems;
+        }
+    };
+    check_naming(
+        content,
+        config.branch_prefix.as_deref(),
+        config.issue_branch_template.as_deref(),
+        &mut problems,
+    );
+    problems
+}
+
+/// Check `branchPrefix` and `issueBranchTemplate`, which both config files accept.
+fn check_naming(
+    content: &str,
+    branch_prefix: Option<&str>,
+    issue_branch_template: Option<&str>,
+    problems: &mut Vec<ConfigProblem>,
+) {
+    if let Some(prefix) = branch_prefix {
+        if let Err(e) = sanitize_branch_prefix(prefix) {
+            problems.push(ConfigProblem {
+                line: line_of_key(content, "branchPrefix"),
+                message: e,
+            });
+        }
+    }
+    if let Some(template) = issue_branch_template {
+        if let Err(e) = validate_issue_branch_template(template) {
+            problems.push(ConfigProblem {
+                line: line_of_key(content, "issueBranchTemplate"),
+                message: e,
+            });
+        }
+    }
+}
+
+fn parse_config_json(content: &str) -> Result<serde_json::Value, ConfigProblem> {
+    let value: serde_json::Value = serde_json::from_str(content).map_err(|e| serde_problem(&e))?;
+    if !value.is_object() {
+        return Err(ConfigProblem {
+            line: Some(1),
+            message: "Config must be a JSON object".to_string(),
+        });
+    }
+    Ok(value)
+}
+
+fn serde_problem(error: &serde_json::Error) -> ConfigProblem {
+    ConfigProblem {
+        line: (error.line() > 0).then_some(error.line()),
+        message: error.to_string(),
+    }
+}
+
+/// Report keys of `value` that grove doesn't know about. Unknown keys are
+/// otherwise ignored at load time, so a typo silently falls back to the default.
+fn check_unknown_keys(
+    content: &str,
+    value: &serde_json::Value,
+    allowed: &[&str],
+    prefix: &str,
+    problems: &mut Vec<ConfigProblem>,
+) {
+    let object = match value.as_object() {
+        Some(object) => object,
+        None => return,
+    };
+    for key in object.keys() {
+        if !allowed.contains(&key.as_str()) {
+            problems.push(ConfigProblem {
+                line: line_of_key(content, key),
+                message: format!(
+                    "Unknown key '{}{}'. Valid keys: {}",
+                    prefix,
+                    key,
+                    allowed.join(", ")
+                ),
+            });
+        }
+    }
+}
+
+/// The first line where `key` appears as an object key.
+fn line_of_key(content: &str, key: &str) -> Option<usize> {
+    let quoted = format!("\"{}\"", key);
+    content
+        .lines()
+        .position(|line| {
+            line.match_indices(&quoted)
+                .any(|(index, _)| line[index + quoted.len()..].trim_start().starts_with(':'))
+        })
+        .map(|index| index + 1)
+}
+
+/// Pick the user's editor from $VISUAL or $EDITOR, falling back to a platform default.
+fn resolve_editor() -> String {
+    for var in ["VISUAL", "EDITOR"] {
+        if let Ok(value) = env::var(var) {
+            if !value.trim().is_empty() {
+                return value;
+            }
+        }
+    }
+
+    if cfg!(windows) {
+        "notepad".to_string()
+    } else {
+        "vi".to_string()
+    }
+}
+
+/// Open `path` in `editor` via a scratch copy and only replace the real file
+/// once the edited content parses. Invalid edits are left in the scratch file
+/// so they aren't lost.
+fn edit_config_file(path: &Path, editor: &str) -> Result<(), String> {
+    if !path.exists() {
+        if let Some(parent) = path.parent() {
+            fs::create_dir_all(parent).map_err(|e| {
+                format!(
+                    "Failed to create config directory {}: {}",
+                    parent.display(),
+                    e
+                )
+            })?;
+        }
+        let defaults = serde_json::to_string_pretty(&GroveConfig::default())
+            .map_err(|e| format!("Failed to serialize default config: {}", e))?;
+        write_file_atomic(path, defaults.as_bytes())
+            .map_err(|e| format!("Failed to create config at {}: {}", path.display(), e))?;
+    }
+
+    let scratch_path = scratch_path_for(path);
+    fs::copy(path, &scratch_path)
+        .map_err(|e| format!("Failed to prepare config for editing: {}", e))?;
+
+    let mut parts = editor.split_whitespace();
+    let program = parts
+        .next()
+        .ok_or_else(|| "No editor configured. Set $EDITOR.".to_string())?;
+    let status = Command::new(program)
+        .args(parts)
+        .arg(&scratch_path)
+        .status()
+        .map_err(|e| format!("Failed to launch editor '{}': {}", editor, e))?;
+    if !status.success() {
+        let _ = fs::remove_file(&scratch_path);
+        return Err(format!(
+            "Editor '{}' exited with {}; config left unchanged",
+            editor, status
+        ));
+    }
+
+    let content = fs::read_to_string(&scratch_path)
+        .map_err(|e| format!("Failed to read edited config: {}", e))?;
+    if let Err(e) = serde_json::from_str::<GroveConfig>(&content) {
+        return Err(format!(
+            "Invalid config: {}. {} was left unchanged; your edits are in {}",
+            e,
+            path.display(),
+            scratch_path.display()
+        ));
+    }
+
+    fs::rename(&scratch_path, path)
+        .map_err(|e| format!("Failed to save config at {}: {}", path.display(), e))
+}
+
+fn scratch_path_for(path: &Path) -> PathBuf {
+    let mut name = path
+        .file_name()
+        .map(|n| n.to_os_string())
+        .unwrap_or_default();
+    name.push(".edit");
+    path.with_file_name(name)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::utils::make_temp_dir;
+
+    /// Run the fake editor through `sh` so the freshly written script never
+    /// has to be exec'd directly (which can race with other tests' forks).
+    #[cfg(unix)]
+    fn write_fake_editor(dir: &Path, content: &str) -> String {
+        let script = dir.join("fake-editor.sh");
+        fs::write(
+            &script,
+            format!("#!/bin/sh\nprintf '%s' '{}' > \"$1\"\n", content),
+        )
+        .unwrap();
+        format!("sh {}", script.display())
+    }
+
+    #[test]
+    fn validate_reports_unknown_key_and_bad_template_with_lines() {
+        let content = r#"{
+  "branchPrefix": "safia",
+  "issueBranchTemplat": "issue-{number}",
+  "issueBranchTemplate": "fix-issue"
+}"#;
+
+        let problems = validate_repo_config(content);
+        assert_eq!(problems.len(), 2);
+        assert_eq!(problems[0].line, Some(3));
+        assert!(problems[0]
+            .message
+            .contains("Unknown key 'issueBranchTemplat'"));
+        assert_eq!(problems[1].line, Some(4));
+        assert!(problems[1].message.contains("{number}"));
+    }
+
+    #[test]
+    fn validate_reports_nested_keys_type_errors_and_syntax() {
+        let content = r#"{
+  "bootstrap": { "commands": [{ "program": "npm", "arg": ["install"] }] }
+}"#;
+        let problems = validate_repo_config(content);
+        assert_eq!(problems.len(), 1);
+        assert!(problems[0].message.contains("'bootstrap.commands[0].arg'"));
+
+        let problems = validate_grove_config("{\n  \"shellTipShown\": \"yes\"\n}");
+        assert_eq!(problems.len(), 1);
+        assert_eq!(problems[0].line, Some(2));
+
+        let problems = validate_grove_config("{\n  \"shellTipShown\": true,\n");
+        assert_eq!(problems.len(), 1);
+        assert!(problems[0].line.is_some());
+
+        assert!(validate_grove_config("{ \"shellTipShown\": true }").is_empty());
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn invalid_edit_preserves_original_config() {
+        let dir = make_temp_dir("config-edit-invalid");
+        let path = dir.join("config.json");
+        let original = "{\n  \"shellTipShown\": true\n}";
+        fs::write(&path, original).unwrap();
+        let editor = write_fake_editor(&dir, "{ not json");
+
+        let err = edit_config_file(&path, &editor).unwrap_err();
+        assert!(err.contains("Invalid config"));
+        assert_eq!(fs::read_to_string(&path).unwrap(), original);
+        assert_eq!(
+            fs::read_to_string(scratch_path_for(&path)).unwrap(),
+            "{ not json"
+        );
+
+        let _ = fs::remove_dir_all(&dir);
+    }
+
+    #[cfg(unix)]
+    #[test]
+    fn valid_edit_creates_and_replaces_config() {
+        let dir = make_temp_dir("config-edit-valid");
+        let path = dir.join("grove").join("config.json");
+        let editor = write_fake_editor(&dir, "{\"shellTipShown\": false}");
+
+        edit_config_file(&path, &editor).unwrap();
+        assert_eq!(
+            fs::read_to_string(&path).unwrap(),
+            "{\"shellTipShown\": false}"
+        );
+        assert!(!scratch_path_for(&path).exists());
+
+        let _ = fs::remove_dir_all(&dir);
+    }
+}
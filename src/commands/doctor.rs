@@ -0,0 +1,355 @@
+use colored::Colorize;
+use serde::Serialize;
+use std::path::Path;
+
+use crate::git::{
+    detach_worktree, discover_repo, list_worktrees, prune_worktree_metadata, repair_worktree,
+    RepoContext, DETACHED_HEAD,
+};
+use crate::models::Worktree;
+use crate::utils::parse_git_file;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "kebab-case")]
+enum IssueKind {
+    /// Git still has metadata for a worktree whose directory is gone.
+    OrphanedMetadata,
+    /// The worktree directory exists but its `.git` link is missing or broken.
+    BrokenGitdir,
+    /// The worktree's branch no longer exists.
+    DanglingBranch,
+    /// Another worktree listed earlier is on the same branch, which git
+    /// normally forbids.
+    DuplicateBranch,
+    Locked,
+}
+
+impl IssueKind {
+    fn label(self) -> &'static str {
+        match self {
+            IssueKind::OrphanedMetadata => "orphaned-metadata",
+            IssueKind::BrokenGitdir => "broken-gitdir",
+            IssueKind::DanglingBranch => "dangling-branch",
+            IssueKind::DuplicateBranch => "duplicate-branch",
+            IssueKind::Locked => "locked",
+        }
+    }
+
+    fn is_fixable(self) -> bool {
+        matches!(
+            self,
+            IssueKind::OrphanedMetadata | IssueKind::BrokenGitdir | IssueKind::DuplicateBranch
+        )
+    }
+}
+
+#[derive(Debug, Serialize)]
+struct DoctorIssue {
+    #[serde(rename = "type")]
+    kind: IssueKind,
+    path: String,
+    message: String,
+    fixed: bool,
+}
+
+#[derive(Debug, Serialize)]
+struct DoctorReport {
+    issues: Vec<DoctorIssue>,
+}
+
+pub fn run(fix: bool, json: bool) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktrees = match list_worktrees(&repo) {
+        Ok(wts) => wts,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let mut issues = find_issues(&worktrees);
+    let fix_errors = if fix {
+        fix_issues(&repo, &mut issues)
+    } else {
+        Vec::new()
+    };
+    let unresolved = issues.iter().filter(|issue| !issue.fixed).count();
+
+    if json {
+        match serde_json::to_string_pretty(&DoctorReport { issues }) {
+            Ok(output) => println!("{}", output),
+            Err(e) => {
+                eprintln!("{} Failed to serialize JSON: {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+    } else {
+        print_issues(&issues, fix);
+    }
+
+    for error in &fix_errors {
+        eprintln!("{} {}", "Warning:".yellow(), error);
+    }
+    if unresolved > 0 {
+        std::process::exit(1);
+    }
+}
+
+fn find_issues(worktrees: &[Worktree]) -> Vec<DoctorIssue> {
+    let mut issues = Vec::new();
+    for (index, wt) in worktrees.iter().enumerate() {
+        let mut push = |kind, message: String| {
+            issues.push(DoctorIssue {
+                kind,
+                path: wt.path.clone(),
+                message,
+                fixed: false,
+            })
+        };
+
+        if !Path::new(&wt.path).exists() {
+            if wt.is_prunable {
+                push(
+                    IssueKind::OrphanedMetadata,
+                    "Worktree directory no longer exists, but git still tracks it".to_string(),
+                );
+            }
+        } else if has_broken_gitdir(Path::new(&wt.path)) {
+            push(
+                IssueKind::BrokenGitdir,
+                "The .git file is missing or doesn't point to the bare clone".to_string(),
+            );
+        }
+        if wt.is_dangling {
+            push(
+                IssueKind::DanglingBranch,
+                format!("Branch '{}' no longer exists", wt.branch),
+            );
+        }
+        if let Some(first) = worktrees[..index]
+            .iter()
+            .find(|other| has_checkout(other) && other.branch == wt.branch)
+            .filter(|_| has_checkout(wt))
+        {
+            push(
+                IssueKind::DuplicateBranch,
+                format!(
+                    "Branch '{}' is also checked out in {}; switch this worktree to another branch or remove it",
+                    wt.branch, first.path
+                ),
+            );
+        }
+        if wt.is_locked {
+            push(
+                IssueKind::Locked,
+                "Worktree is locked; unlock it with 'git worktree unlock'".to_string(),
+            );
+        }
+    }
+    issues
+}
+
+/// Whether `wt` has a branch checked out in a directory that still exists.
+fn has_checkout(wt: &Worktree) -> bool {
+    wt.branch != DETACHED_HEAD && !wt.branch.is_empty() && Path::new(&wt.path).exists()
+}
+
+/// A linked worktree's `.git` file must name an existing admin directory.
+fn has_broken_gitdir(worktree_path: &Path) -> bool {
+    match parse_git_file(&worktree_path.join(".git")) {
+        Ok(gitdir) => !worktree_path.join(gitdir).exists(),
+        Err(_) => true,
+    }
+}
+
+/// Fix what can be fixed and mark it. Broken links are repaired before
+/// pruning, since git also counts them as prunable and would otherwise drop
+/// their metadata. Returns errors for issues that couldn't be fixed.
+fn fix_issues(repo: &RepoContext, issues: &mut [DoctorIssue]) -> Vec<String> {
+    let mut errors = Vec::new();
+
+    for issue in issues
+        .iter_mut()
+        .filter(|issue| issue.kind == IssueKind::BrokenGitdir)
+    {
+        // git can report failure after rewriting the .git file, so check the result.
+        let result = repair_worktree(repo, &issue.path);
+        issue.fixed = !has_broken_gitdir(Path::new(&issue.path));
+        if let (false, Err(e)) = (issue.fixed, result) {
+            errors.push(e);
+        }
+    }
+
+    for issue in issues
+        .iter_mut()
+        .filter(|issue| issue.kind == IssueKind::DuplicateBranch)
+    {
+        match detach_worktree(repo, &issue.path) {
+            Ok(()) => issue.fixed = true,
+            Err(e) => errors.push(e),
+        }
+    }
+
+    if issues
+        .iter()
+        .any(|issue| issue.kind == IssueKind::OrphanedMetadata)
+    {
+        match prune_worktree_metadata(repo) {
+            Ok(()) => issues
+                .iter_mut()
+                .filter(|issue| issue.kind == IssueKind::OrphanedMetadata)
+                .for_each(|issue| issue.fixed = true),
+            Err(e) => errors.push(e),
+        }
+    }
+
+    errors
+}
+
+fn print_issues(issues: &[DoctorIssue], fix: bool) {
+    if issues.is_empty() {
+        println!("{}", "✓ No problems found".green());
+        return;
+    }
+
+    for issue in issues {
+        if issue.fixed {
+            println!(
+                "{} {} {}",
+                "✓ Fixed".green(),
+                format!("[{}]", issue.kind.label()).bold(),
+                issue.path
+            );
+        } else {
+            println!(
+                "{} {} {}",
+                "✗".red(),
+                format!("[{}]", issue.kind.label()).bold(),
+                issue.path
+            );
+            println!("  {}", issue.message.dimmed());
+        }
+    }
+
+    if !fix && issues.iter().any(|issue| issue.kind.is_fixable()) {
+        println!();
+        println!(
+            "{}",
+            "Run 'grove doctor --fix' to prune orphaned metadata, repair broken .git links, and detach duplicate branches."
+                .dimmed()
+        );
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, repo_path};
+    use std::fs;
+
+    #[test]
+    fn json_report_lists_orphaned_metadata() {
+        let repo = create_test_repo("doctor-orphaned");
+        let gone = repo.add_worktree("feature-gone");
+        repo.add_worktree("feature-ok");
+        fs::remove_dir_all(&gone).unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let issues = find_issues(&worktrees);
+        let report = serde_json::to_value(DoctorReport { issues }).unwrap();
+
+        let gone_path = worktrees
+            .iter()
+            .find(|wt| wt.branch == "feature-gone")
+            .unwrap()
+            .path
+            .clone();
+        assert_eq!(
+            report,
+            serde_json::json!({
+                "issues": [{
+                    "type": "orphaned-metadata",
+                    "path": gone_path,
+                    "message": "Worktree directory no longer exists, but git still tracks it",
+                    "fixed": false,
+                }]
+            })
+        );
+    }
+
+    #[test]
+    fn fix_prunes_orphans_and_repairs_broken_links() {
+        let repo = create_test_repo("doctor-fix");
+        let gone = repo.add_worktree("feature-gone");
+        let broken = repo.add_worktree("feature-broken");
+        fs::remove_dir_all(&gone).unwrap();
+        fs::remove_file(broken.join(".git")).unwrap();
+
+        let mut issues = find_issues(&list_worktrees(&repo.context).unwrap());
+        let kinds: Vec<IssueKind> = issues.iter().map(|issue| issue.kind).collect();
+        assert_eq!(
+            kinds,
+            vec![IssueKind::BrokenGitdir, IssueKind::OrphanedMetadata]
+        );
+
+        assert!(fix_issues(&repo.context, &mut issues).is_empty());
+        assert!(issues.iter().all(|issue| issue.fixed));
+        assert!(find_issues(&list_worktrees(&repo.context).unwrap()).is_empty());
+    }
+
+    #[test]
+    fn duplicate_branch_is_detected_and_fixed_by_detaching() {
+        let repo = create_test_repo("doctor-duplicate");
+        let first = repo.add_worktree("feature-a");
+        let second = repo.add_worktree("feature-b");
+        // git refuses to do this itself, so point feature-b's HEAD at feature-a by hand.
+        fs::write(
+            repo_path(&repo.context)
+                .join("worktrees")
+                .join("feature-b")
+                .join("HEAD"),
+            "ref: refs/heads/feature-a\n",
+        )
+        .unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let first_path = worktrees
+            .iter()
+            .find(|wt| Path::new(&wt.path) == first)
+            .unwrap()
+            .path
+            .clone();
+        let mut issues = find_issues(&worktrees);
+        assert_eq!(issues.len(), 1);
+        assert_eq!(issues[0].kind, IssueKind::DuplicateBranch);
+        assert_eq!(Path::new(&issues[0].path), second);
+        assert!(issues[0].message.contains(&first_path));
+
+        assert!(fix_issues(&repo.context, &mut issues).is_empty());
+        assert!(issues[0].fixed);
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert!(find_issues(&worktrees).is_empty());
+        let detached = worktrees
+            .iter()
+            .find(|wt| Path::new(&wt.path) == second)
+            .unwrap();
+        assert_eq!(detached.branch, DETACHED_HEAD);
+    }
+
+    #[test]
+    fn locked_and_dangling_are_reported_but_not_fixable() {
+        assert!(!IssueKind::Locked.is_fixable());
+        assert!(!IssueKind::DanglingBranch.is_fixable());
+        assert_eq!(
+            serde_json::to_value(IssueKind::DanglingBranch).unwrap(),
+            "dangling-branch"
+        );
+    }
+}
@@ -0,0 +1,140 @@
+use colored::Colorize;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use crate::git::{
+    discover_repo, get_default_branch, list_worktrees, project_root, remote_url, DETACHED_HEAD,
+};
+use crate::inventory::{render_inventory, Inventory, InventoryEntry};
+use crate::models::Worktree;
+
+pub fn run() {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktrees = match list_worktrees(&repo) {
+        Ok(wts) => wts,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let (entries, skipped) = inventory_entries(&worktrees, project_root(&repo));
+    let inventory = Inventory {
+        url: remote_url(&repo),
+        base: get_default_branch(&repo).ok(),
+        worktrees: entries,
+    };
+
+    // Skipped worktrees go to stderr so redirected output stays a clean inventory.
+    for (path, reason) in &skipped {
+        eprintln!("{}", format!("Skipping {}: {}", path, reason).yellow());
+    }
+
+    print!("{}", render_inventory(&inventory));
+}
+
+/// Turn worktrees into inventory entries with directories relative to the
+/// project root. Worktrees that can't be recreated elsewhere are skipped.
+fn inventory_entries(
+    worktrees: &[Worktree],
+    project_root: &Path,
+) -> (Vec<InventoryEntry>, Vec<(String, &'static str)>) {
+    let root = fs::canonicalize(project_root).unwrap_or_else(|_| project_root.to_path_buf());
+    let mut entries = Vec::new();
+    let mut skipped = Vec::new();
+
+    for wt in worktrees {
+        if wt.is_main {
+            continue;
+        }
+        if wt.branch == DETACHED_HEAD {
+            skipped.push((wt.path.clone(), "detached HEAD"));
+            continue;
+        }
+        if wt.is_dangling {
+            skipped.push((wt.path.clone(), "branch no longer exists"));
+            continue;
+        }
+
+        let path = fs::canonicalize(&wt.path).unwrap_or_else(|_| PathBuf::from(&wt.path));
+        let directory = match path.strip_prefix(&root) {
+            Ok(rel) if !rel.as_os_str().is_empty() => rel
+                .components()
+                .map(|c| c.as_os_str().to_string_lossy().to_string())
+                .collect::<Vec<_>>()
+                .join("/"),
+            _ => {
+                skipped.push((wt.path.clone(), "outside the project root"));
+                continue;
+            }
+        };
+
+        entries.push(InventoryEntry {
+            branch: wt.branch.clone(),
+            directory,
+            created_at: Some(wt.created_at),
+        });
+    }
+
+    (entries, skipped)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::commands::add::WorktreeSetup;
+    use crate::commands::sync::restore_inventory;
+    use crate::git::{clone_bare_repository, create_test_repo, open_repo, run_test_git};
+    use crate::inventory::parse_inventory;
+    use crate::utils::make_temp_dir;
+
+    #[test]
+    fn export_then_sync_recreates_worktrees_in_fresh_clone() {
+        let repo = create_test_repo("export-round-trip");
+        let feature = repo.add_worktree("feature-a");
+        fs::write(feature.join("a.txt"), "a").unwrap();
+        run_test_git(&feature, &["add", "a.txt"]);
+        run_test_git(&feature, &["commit", "-q", "-m", "a"]);
+        run_test_git(&feature, &["push", "-q", "origin", "feature-a"]);
+        let nested = repo.add_worktree("nested/b");
+        run_test_git(&nested, &["push", "-q", "origin", "nested/b"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let (entries, skipped) = inventory_entries(&worktrees, project_root(&repo.context));
+        assert!(skipped.is_empty());
+        let exported = render_inventory(&Inventory {
+            url: remote_url(&repo.context),
+            base: get_default_branch(&repo.context).ok(),
+            worktrees: entries,
+        });
+        assert_eq!(exported.matches("# created ").count(), 2);
+
+        let inventory = parse_inventory(&exported).unwrap();
+        let fresh_dir = make_temp_dir("export-round-trip-fresh");
+        let bare = fresh_dir.join("fresh.git");
+        clone_bare_repository(inventory.url.as_deref().unwrap(), &bare.to_string_lossy()).unwrap();
+        let fresh = open_repo(&bare, &fresh_dir);
+
+        let results = restore_inventory(&fresh, &inventory, &WorktreeSetup::default(), 1);
+        assert!(results.iter().all(|(_, result)| result.is_ok()));
+
+        let mut restored: Vec<String> = list_worktrees(&fresh)
+            .unwrap()
+            .into_iter()
+            .map(|wt| wt.branch)
+            .collect();
+        restored.sort();
+        assert_eq!(restored, vec!["feature-a", "nested/b"]);
+        assert!(fresh_dir.join("feature-a").join("a.txt").is_file());
+        assert!(fresh_dir.join("nested").join("b").is_dir());
+
+        let _ = fs::remove_dir_all(&fresh_dir);
+    }
+}
@@ -0,0 +1,60 @@
+use colored::Colorize;
+
+use crate::git::{
+    discover_repo, gc_repository, object_counts, operation_in_progress, ObjectCounts,
+};
+use crate::utils::format_size;
+
+pub fn run(aggressive: bool, prune: Option<&str>) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    // gc repacks and prunes objects out from under a running git command.
+    if let Some(operation) = operation_in_progress(&repo) {
+        eprintln!(
+            "{} {} appears to be in progress. Finish it and try again.",
+            "Error:".red(),
+            operation
+        );
+        std::process::exit(1);
+    }
+
+    let before = object_counts(&repo);
+    println!("{}", "Running git gc...".blue());
+    if let Err(e) = gc_repository(&repo, aggressive, prune) {
+        eprintln!("{} {}", "Error:".red(), e);
+        std::process::exit(1);
+    }
+    println!("{}", "✓ Garbage collection complete".green());
+
+    if let (Ok(before), Ok(after)) = (before, object_counts(&repo)) {
+        for line in format_object_changes(&before, &after) {
+            println!("  {}", line.dimmed());
+        }
+    }
+}
+
+fn format_object_changes(before: &ObjectCounts, after: &ObjectCounts) -> Vec<String> {
+    let size = |kb: u64| format_size(kb * 1024);
+    vec![
+        format!(
+            "Loose objects: {} ({}) → {} ({})",
+            before.loose,
+            size(before.loose_size_kb),
+            after.loose,
+            size(after.loose_size_kb)
+        ),
+        format!(
+            "Packs: {} ({}) → {} ({})",
+            before.packs,
+            size(before.pack_size_kb),
+            after.packs,
+            size(after.pack_size_kb)
+        ),
+    ]
+}
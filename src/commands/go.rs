@@ -1,14 +1,34 @@
 use colored::Colorize;
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::path::PathBuf;
 use std::process::Command;
 
+use crate::commands::add::create_branch_worktree;
 use crate::commands::shell_init::{
     get_shell_setup_instructions, mark_shell_tip_shown, should_show_shell_tip,
 };
-use crate::git::{discover_repo, find_worktree_by_name, list_worktrees, RepoContext};
+use crate::git::{
+    discover_repo, get_worktree, get_worktree_by, list_worktrees, repo_path, MatchBy, RepoContext,
+    WorktreeLookupError,
+};
 use crate::models::Worktree;
+use crate::prompt::choose_worktree;
 use crate::utils::{get_shell_for_platform, trim_trailing_branch_slashes};
 
-pub fn run(name: Option<&str>, path_only: bool) {
+/// Name that jumps back to the previously visited worktree, like `cd -`.
+const PREVIOUS_WORKTREE: &str = "-";
+
+/// Which worktrees `grove go` last visited, kept in `<bare clone>/grove-state`.
+#[derive(Debug, Default, Serialize, Deserialize)]
+struct SwitchState {
+    #[serde(default)]
+    current: Option<String>,
+    #[serde(default)]
+    previous: Option<String>,
+}
+
+pub fn run(name: Option<&str>, path_only: bool, create: bool, by: MatchBy) {
     if path_only
         && name
             .map(|n| trim_trailing_branch_slashes(n).is_empty())
@@ -30,20 +50,27 @@ pub fn run(name: Option<&str>, path_only: bool) {
         }
     };
 
-    let worktree = if let Some(name) = name {
+    let worktree = if name == Some(PREVIOUS_WORKTREE) {
+        match previous_worktree(&repo) {
+            Ok(wt) => wt,
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+    } else if let Some(name) = name {
         let normalized_name = trim_trailing_branch_slashes(name);
         if normalized_name.is_empty() {
             pick_or_error(&repo)
         } else {
-            match find_worktree_by_name(&repo, normalized_name) {
-                Ok(Some(wt)) => wt,
-                Ok(None) => {
-                    eprintln!(
-                        "{} Worktree '{}' not found. Use 'grove list' to see available worktrees.",
-                        "Error:".red(),
-                        name
-                    );
-                    std::process::exit(1);
+            match find_or_create_worktree(&repo, normalized_name, create, by) {
+                // Shell integration captures stdout and stderr as the path, so
+                // only mention the new worktree when navigating directly.
+                Ok((wt, created)) => {
+                    if created && !path_only {
+                        println!("{} {}", "✓ Created worktree:".green(), wt.branch.bold());
+                    }
+                    wt
                 }
                 Err(e) => {
                     eprintln!("{} {}", "Error:".red(), e);
@@ -55,9 +82,68 @@ pub fn run(name: Option<&str>, path_only: bool) {
         pick_or_error(&repo)
     };
 
+    record_switch(&repo, &worktree.path);
     navigate_to_worktree(&worktree, path_only);
 }
 
+/// Look up the worktree for `name`; with `create`, a name that matches nothing
+/// gets a new worktree instead. The flag is true when the worktree was created.
+fn find_or_create_worktree(
+    repo: &RepoContext,
+    name: &str,
+    create: bool,
+    by: MatchBy,
+) -> Result<(Worktree, bool), String> {
+    // With `create`, a partial name creates a worktree rather than jumping to
+    // whichever existing one contains it.
+    let by = if create { by.exact() } else { by };
+    match get_worktree_by(repo, name, by) {
+        Ok(wt) => Ok((wt, false)),
+        Err(WorktreeLookupError::NotFound(_)) if create => {
+            let (path, _) = create_branch_worktree(repo, name)?;
+            let wt = get_worktree(repo, &path.to_string_lossy()).map_err(|e| e.to_string())?;
+            Ok((wt, true))
+        }
+        Err(e) => Err(e.to_string()),
+    }
+}
+
+fn state_path(repo: &RepoContext) -> PathBuf {
+    repo_path(repo).join("grove-state")
+}
+
+fn read_switch_state(repo: &RepoContext) -> SwitchState {
+    fs::read_to_string(state_path(repo))
+        .ok()
+        .and_then(|content| serde_json::from_str(&content).ok())
+        .unwrap_or_default()
+}
+
+/// Remember `path` as the current worktree. The one it replaces becomes the
+/// target of `grove go -`. State is best-effort and never blocks navigation.
+fn record_switch(repo: &RepoContext, path: &str) {
+    let mut state = read_switch_state(repo);
+    if state.current.as_deref() == Some(path) {
+        return;
+    }
+    state.previous = state.current.take();
+    state.current = Some(path.to_string());
+    if let Ok(content) = serde_json::to_string_pretty(&state) {
+        let _ = fs::write(state_path(repo), content);
+    }
+}
+
+fn previous_worktree(repo: &RepoContext) -> Result<Worktree, String> {
+    let previous = read_switch_state(repo).previous.ok_or_else(|| {
+        "No previous worktree. Use 'grove go <name>' to switch between worktrees first.".to_string()
+    })?;
+
+    list_worktrees(repo)?
+        .into_iter()
+        .find(|wt| wt.path == previous)
+        .ok_or_else(|| format!("Previous worktree {} no longer exists.", previous))
+}
+
 fn pick_or_error(repo: &RepoContext) -> Worktree {
     let worktrees = match list_worktrees(repo) {
         Ok(wts) => wts,
@@ -72,31 +158,21 @@ fn pick_or_error(repo: &RepoContext) -> Worktree {
         std::process::exit(1);
     }
 
-    if !atty::is(atty::Stream::Stdin) {
-        eprintln!("{}", "Error: Interactive selection requires a TTY.".red());
-        eprintln!(
-            "{}",
-            "Provide a branch name argument or use 'grove list' instead.".dimmed()
-        );
-        std::process::exit(1);
-    }
-
-    let items: Vec<String> = worktrees
-        .iter()
-        .map(|wt| format!("{} ({})", wt.branch, wt.path))
-        .collect();
-
-    let selection: Result<Option<usize>, _> = dialoguer::FuzzySelect::new()
-        .with_prompt("Select a worktree (type to search)")
-        .items(&items)
-        .interact_opt();
-
-    match selection {
-        Ok(Some(idx)) => worktrees[idx].clone(),
-        _ => {
+    let choices: Vec<&Worktree> = worktrees.iter().collect();
+    match choose_worktree(&choices, "Select a worktree") {
+        Ok(Some(wt)) => wt.clone(),
+        Ok(None) => {
             println!("{}", "Selection cancelled.".dimmed());
             std::process::exit(0);
         }
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            eprintln!(
+                "{}",
+                "Provide a branch name argument or use 'grove list' instead.".dimmed()
+            );
+            std::process::exit(1);
+        }
     }
 }
 
@@ -148,3 +224,72 @@ fn navigate_to_worktree(worktree: &Worktree, path_only: bool) {
 
     println!("{}", "Exited worktree shell.".dimmed());
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, remove_worktree};
+    use std::path::Path;
+
+    #[test]
+    fn create_switches_to_existing_or_creates_missing_worktree() {
+        let repo = create_test_repo("go-create");
+        let existing = repo.add_worktree("feature-a");
+
+        let (found, created) =
+            find_or_create_worktree(&repo.context, "feature-a", true, MatchBy::Any).unwrap();
+        assert!(!created);
+        assert_eq!(found.path, existing.to_string_lossy());
+
+        let (found, created) =
+            find_or_create_worktree(&repo.context, "feat", false, MatchBy::Any).unwrap();
+        assert!(!created);
+        assert_eq!(found.path, existing.to_string_lossy());
+        let (feat, created) =
+            find_or_create_worktree(&repo.context, "feat", true, MatchBy::Any).unwrap();
+        assert!(created);
+        assert_eq!(feat.branch, "feat");
+
+        let err =
+            find_or_create_worktree(&repo.context, "feature/x", false, MatchBy::Any).unwrap_err();
+        assert!(err.contains("not found"));
+
+        let (wt, created) =
+            find_or_create_worktree(&repo.context, "feature/x", true, MatchBy::Any).unwrap();
+        assert!(created);
+        assert_eq!(wt.branch, "feature/x");
+        assert!(wt.path.ends_with("feature-x"));
+        assert!(Path::new(&wt.path).is_dir());
+
+        let (again, created) =
+            find_or_create_worktree(&repo.context, "feature/x", true, MatchBy::Any).unwrap();
+        assert!(!created);
+        assert_eq!(again.path, wt.path);
+    }
+
+    #[test]
+    fn dash_resolves_to_the_worktree_switched_from() {
+        let repo = create_test_repo("go-previous");
+        let first = repo.add_worktree("feature-a");
+        let second = repo.add_worktree("feature-b");
+        let first = first.to_string_lossy().to_string();
+        let second = second.to_string_lossy().to_string();
+
+        assert!(previous_worktree(&repo.context)
+            .unwrap_err()
+            .contains("No previous worktree"));
+
+        record_switch(&repo.context, &first);
+        record_switch(&repo.context, &second);
+        assert_eq!(previous_worktree(&repo.context).unwrap().path, first);
+
+        // Going back swaps the pair, so `-` toggles like `cd -`.
+        record_switch(&repo.context, &first);
+        assert_eq!(previous_worktree(&repo.context).unwrap().path, second);
+
+        remove_worktree(&repo.context, &second, true).unwrap();
+        assert!(previous_worktree(&repo.context)
+            .unwrap_err()
+            .contains("no longer exists"));
+    }
+}
@@ -0,0 +1,116 @@
+use colored::Colorize;
+use std::env;
+use std::path::{Path, PathBuf};
+
+use crate::commands::add::rerun_bootstrap;
+use crate::commands::worktree_root::find_worktree_root;
+use crate::git::{discover_repo, get_worktree, project_root};
+use crate::utils::{load_repo_config, BootstrapCommand};
+
+/// Hooks that can be run by hand. `bootstrap` is what `grove add` runs in a
+/// new worktree.
+const HOOKS: &[&str] = &["bootstrap"];
+
+pub fn run(name: &str, worktree: Option<&str>) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktree_path = match worktree {
+        Some(worktree) => match get_worktree(&repo, worktree) {
+            Ok(wt) => PathBuf::from(wt.path),
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        },
+        None => {
+            let cwd = env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+            match find_worktree_root(&cwd) {
+                Some(root) => root,
+                None => {
+                    eprintln!(
+                        "{} Not inside a grove worktree. Name the worktree to run the hook in.",
+                        "Error:".red()
+                    );
+                    std::process::exit(1);
+                }
+            }
+        }
+    };
+
+    let commands = match hook_commands(project_root(&repo), name) {
+        Ok(commands) => commands,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    if commands.is_empty() {
+        println!(
+            "{}",
+            format!("No {} commands configured in .groverc.", name).yellow()
+        );
+        return;
+    }
+
+    println!(
+        "{} {}",
+        format!("Running {} hook in", name).dimmed(),
+        worktree_path.display()
+    );
+    if !rerun_bootstrap(&worktree_path, &commands) {
+        std::process::exit(1);
+    }
+}
+
+/// The commands configured for hook `name`, failing for names grove doesn't
+/// know rather than silently running nothing.
+fn hook_commands(project_root: &Path, name: &str) -> Result<Vec<BootstrapCommand>, String> {
+    if !HOOKS.contains(&name) {
+        return Err(format!(
+            "Unknown hook '{}'. Available hooks: {}",
+            name,
+            HOOKS.join(", ")
+        ));
+    }
+    let config = load_repo_config(project_root)?;
+    Ok(config
+        .bootstrap
+        .map(|bootstrap| bootstrap.commands)
+        .unwrap_or_default())
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::create_test_repo;
+    use std::fs;
+
+    #[cfg(unix)]
+    #[test]
+    fn bootstrap_hook_runs_in_an_existing_worktree() {
+        let repo = create_test_repo("hooks-run");
+        let worktree = repo.add_worktree("feature-a");
+        fs::write(
+            project_root(&repo.context).join(".groverc"),
+            r#"{"bootstrap": {"commands": [{"program": "sh", "args": ["-c", "touch hooked"]}]}}"#,
+        )
+        .unwrap();
+
+        let commands = hook_commands(project_root(&repo.context), "bootstrap").unwrap();
+        assert!(rerun_bootstrap(&worktree, &commands));
+        assert!(worktree.join("hooked").exists());
+    }
+
+    #[test]
+    fn unknown_hooks_are_rejected() {
+        let repo = create_test_repo("hooks-unknown");
+        let err = hook_commands(project_root(&repo.context), "pre_remove").unwrap_err();
+        assert!(err.contains("Available hooks: bootstrap"));
+    }
+}
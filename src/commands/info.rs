@@ -0,0 +1,163 @@
+use colored::Colorize;
+use serde::Serialize;
+use std::env;
+use std::path::{Path, PathBuf};
+
+use crate::git::{get_default_branch, git_dir_info, list_worktrees, open_repo, project_root};
+use crate::utils::{
+    discover_bare_clone, format_path_with_tilde, get_config_path, get_project_root,
+    DiscoveryErrorKind,
+};
+
+#[derive(Debug, Serialize)]
+struct RepoInfo {
+    #[serde(rename = "groveManaged")]
+    grove_managed: bool,
+    #[serde(rename = "gitDir")]
+    git_dir: String,
+    #[serde(rename = "isBare")]
+    is_bare: bool,
+    #[serde(rename = "defaultBranch")]
+    default_branch: Option<String>,
+    #[serde(rename = "projectRoot")]
+    project_root: Option<String>,
+    #[serde(rename = "worktreeCount")]
+    worktree_count: usize,
+    #[serde(rename = "configPath")]
+    config_path: String,
+}
+
+pub fn run(json: bool) {
+    let cwd = env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    let info = match collect_info(&cwd) {
+        Ok(info) => info,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    if json {
+        match serde_json::to_string_pretty(&info) {
+            Ok(output) => println!("{}", output),
+            Err(e) => {
+                eprintln!("{} Failed to serialize JSON: {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    let layout = if info.grove_managed {
+        "grove (bare clone with worktrees)".green()
+    } else {
+        "regular git repository (not managed by grove)".yellow()
+    };
+    println!("{} {}", "Layout:        ".dimmed(), layout);
+    println!(
+        "{} {}",
+        "Git dir:       ".dimmed(),
+        format_path_with_tilde(&info.git_dir)
+    );
+    println!(
+        "{} {}",
+        "Bare:          ".dimmed(),
+        if info.is_bare { "yes" } else { "no" }
+    );
+    println!(
+        "{} {}",
+        "Default branch:".dimmed(),
+        info.default_branch.as_deref().unwrap_or("unknown")
+    );
+    println!(
+        "{} {}",
+        "Project root:  ".dimmed(),
+        info.project_root
+            .as_deref()
+            .map(format_path_with_tilde)
+            .unwrap_or_else(|| "n/a".to_string())
+    );
+    println!("{} {}", "Worktrees:     ".dimmed(), info.worktree_count);
+    println!(
+        "{} {}",
+        "Config:        ".dimmed(),
+        format_path_with_tilde(&info.config_path)
+    );
+}
+
+/// Gather repository metadata starting from `start`. Regular repositories are
+/// reported too, so users can see why grove doesn't recognize them.
+fn collect_info(start: &Path) -> Result<RepoInfo, String> {
+    let (context, grove_managed) = match discover_bare_clone(Some(start)) {
+        Ok(bare_clone_path) => {
+            let root = get_project_root(&bare_clone_path);
+            (open_repo(&bare_clone_path, &root), true)
+        }
+        Err(e) if e.kind == DiscoveryErrorKind::NotGroveManaged => (open_repo(start, start), false),
+        Err(e) => return Err(e.to_string()),
+    };
+
+    let (git_dir, is_bare) = git_dir_info(&context)?;
+    let worktree_count = list_worktrees(&context)?.len();
+
+    Ok(RepoInfo {
+        grove_managed,
+        git_dir: git_dir.to_string_lossy().to_string(),
+        is_bare,
+        default_branch: get_default_branch(&context).ok(),
+        project_root: grove_managed.then(|| project_root(&context).to_string_lossy().to_string()),
+        worktree_count,
+        config_path: get_config_path().to_string_lossy().to_string(),
+    })
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, repo_path, run_test_git};
+    use crate::utils::{env_lock, make_temp_dir};
+
+    fn collect_info_without_env_cache(start: &Path) -> Result<RepoInfo, String> {
+        let _guard = env_lock().lock().unwrap();
+        let original = env::var("GROVE_REPO").ok();
+        env::remove_var("GROVE_REPO");
+        let result = collect_info(start);
+        if let Some(value) = original {
+            env::set_var("GROVE_REPO", value);
+        }
+        result
+    }
+
+    #[test]
+    fn grove_layout_reports_bare_clone() {
+        let repo = create_test_repo("info-grove");
+        let worktree = repo.add_worktree("feature-a");
+
+        let info = collect_info_without_env_cache(&worktree).unwrap();
+        assert!(info.grove_managed);
+        assert!(info.is_bare);
+        assert_eq!(
+            Path::new(&info.git_dir),
+            std::fs::canonicalize(repo_path(&repo.context)).unwrap()
+        );
+        assert_eq!(info.default_branch.as_deref(), Some("main"));
+        assert_eq!(info.worktree_count, 1);
+    }
+
+    #[test]
+    fn regular_repo_reports_not_bare() {
+        let dir = make_temp_dir("info-regular");
+        run_test_git(&dir, &["init", "-q", "-b", "main"]);
+        run_test_git(&dir, &["commit", "-q", "--allow-empty", "-m", "init"]);
+
+        let info = collect_info_without_env_cache(&dir).unwrap();
+        assert!(!info.grove_managed);
+        assert!(!info.is_bare);
+        assert!(info.project_root.is_none());
+        assert_eq!(info.worktree_count, 1);
+
+        let value = serde_json::to_value(&info).unwrap();
+        assert_eq!(value["isBare"], false);
+        let _ = std::fs::remove_dir_all(dir);
+    }
+}
@@ -1,11 +1,14 @@
 use colored::Colorize;
 use std::fs;
-use std::path::Path;
+use std::path::{Path, PathBuf};
 
-use crate::git::clone_bare_repository;
-use crate::utils::{extract_repo_name, find_grove_repo};
+use crate::commands::add::get_worktree_path;
+use crate::git::{
+    add_worktree, clone_bare_repository, get_default_branch, open_repo, project_root, RepoContext,
+};
+use crate::utils::{extract_repo_name, find_grove_repo, slugify_branch};
 
-pub fn run(git_url: &str) {
+pub fn run(git_url: &str, checkout_default: bool) {
     // Check if we're inside an existing grove repository
     if let Some(existing) = find_grove_repo(None) {
         eprintln!(
@@ -65,8 +68,82 @@ pub fn run(git_url: &str) {
         repo_name.bold()
     );
     println!("  {} {}", "Bare repository:".dimmed(), bare_repo_dir);
+
+    let repo = open_repo(Path::new(&bare_repo_dir), Path::new(&repo_name));
+    let default_worktree = match setup_default_worktree(&repo, checkout_default) {
+        Ok(worktree) => worktree,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
     println!();
     println!("{}", "Next steps:".bold());
-    println!("  {} {}", "cd".dimmed(), bare_repo_dir);
-    println!("  {} <branch-name>", "grove add".dimmed());
+    match default_worktree {
+        Some((branch, path)) => {
+            println!(
+                "  {} {}",
+                "Default branch worktree:".dimmed(),
+                branch.bold()
+            );
+            println!("  {} {}", "cd".dimmed(), path.display());
+        }
+        None => {
+            println!("  {} {}", "cd".dimmed(), bare_repo_dir);
+            println!("  {} <branch-name>", "grove add".dimmed());
+        }
+    }
+}
+
+/// With `checkout`, add a worktree for the default branch right after the
+/// clone and return its branch and path. Otherwise only the bare clone exists.
+fn setup_default_worktree(
+    repo: &RepoContext,
+    checkout: bool,
+) -> Result<Option<(String, PathBuf)>, String> {
+    if !checkout {
+        return Ok(None);
+    }
+
+    let branch = get_default_branch(repo)?;
+    let path = get_worktree_path(&slugify_branch(&branch), project_root(repo))?;
+    add_worktree(repo, &path.to_string_lossy(), &branch, false, None)?;
+    Ok(Some((branch, path)))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, list_worktrees};
+
+    #[test]
+    fn checkout_default_adds_default_branch_worktree() {
+        let repo = create_test_repo("init-checkout-default");
+
+        let (branch, path) = setup_default_worktree(&repo.context, true)
+            .unwrap()
+            .unwrap();
+        assert_eq!(branch, "main");
+        assert_eq!(path, project_root(&repo.context).join("main"));
+        assert!(path.join("README.md").exists());
+        assert!(list_worktrees(&repo.context)
+            .unwrap()
+            .iter()
+            .any(|wt| wt.branch == "main"));
+    }
+
+    #[test]
+    fn no_checkout_default_leaves_only_the_bare_clone() {
+        let repo = create_test_repo("init-no-checkout-default");
+
+        assert!(setup_default_worktree(&repo.context, false)
+            .unwrap()
+            .is_none());
+        assert!(!project_root(&repo.context).join("main").exists());
+        assert!(!list_worktrees(&repo.context)
+            .unwrap()
+            .iter()
+            .any(|wt| wt.branch == "main"));
+    }
 }
@@ -1,11 +1,100 @@
+use chrono::{DateTime, Utc};
 use colored::Colorize;
+use serde::Serialize;
+use std::path::Path;
 
-use crate::git::{discover_repo, list_worktrees};
-use crate::models::{Worktree, WorktreeListOptions};
-use crate::utils::{format_created_time, format_path_with_tilde};
+use crate::git::{
+    commit_signature, commit_time, commits_ahead, describe_commit, dirty_file_counts,
+    discover_repo, for_each_worktree, get_default_branch, last_commit_summary, list_worktrees_with,
+    project_root, registered_at, repo_path, resolve_revision, touched_at, unpushed_commits,
+    upstream_branch, CommitSignature, DirtyCheck, DirtyFileCounts, RepoContext, DETACHED_HEAD,
+};
+use crate::models::{TableSpacing, Worktree, WorktreeListOptions};
+use crate::timing::time;
+use crate::utils::{
+    branch_glob_matches, directory_size, format_created_time, format_created_timestamp,
+    format_path_with_tilde, format_size, parallel_map, parse_duration, read_ignore_patterns,
+    relative_path,
+};
 
-pub fn run(details: bool, dirty: bool, locked: bool, json: bool) {
-    let repo = match discover_repo() {
+/// A column selectable with `--fields`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum ListField {
+    Path,
+    Branch,
+    Head,
+    Created,
+    Status,
+    Upstream,
+    Size,
+    LastCommit,
+}
+
+impl ListField {
+    const ALL: [ListField; 8] = [
+        ListField::Path,
+        ListField::Branch,
+        ListField::Head,
+        ListField::Created,
+        ListField::Status,
+        ListField::Upstream,
+        ListField::Size,
+        ListField::LastCommit,
+    ];
+
+    fn name(self) -> &'static str {
+        match self {
+            ListField::Path => "path",
+            ListField::Branch => "branch",
+            ListField::Head => "head",
+            ListField::Created => "created",
+            ListField::Status => "status",
+            ListField::Upstream => "upstream",
+            ListField::Size => "size",
+            ListField::LastCommit => "last-commit",
+        }
+    }
+
+    /// Key used when the field is emitted as JSON, matching the Worktree serialization.
+    fn json_key(self) -> &'static str {
+        match self {
+            ListField::Created => "createdAt",
+            ListField::LastCommit => "lastCommit",
+            other => other.name(),
+        }
+    }
+}
+
+fn parse_fields(value: &str) -> Result<Vec<ListField>, String> {
+    value
+        .split(',')
+        .map(str::trim)
+        .filter(|name| !name.is_empty())
+        .map(|name| {
+            ListField::ALL
+                .into_iter()
+                .find(|field| field.name() == name)
+                .ok_or_else(|| {
+                    let valid: Vec<&str> = ListField::ALL.iter().map(|f| f.name()).collect();
+                    format!(
+                        "Invalid field '{}'. Valid fields: {}",
+                        name,
+                        valid.join(", ")
+                    )
+                })
+        })
+        .collect::<Result<Vec<_>, _>>()
+        .and_then(|fields| {
+            if fields.is_empty() {
+                Err("--fields requires at least one field".to_string())
+            } else {
+                Ok(fields)
+            }
+        })
+}
+
+pub fn run(options: &WorktreeListOptions) {
+    let repo = match time("repo discovery", discover_repo) {
         Ok(m) => m,
         Err(e) => {
             eprintln!("{} {}", "Error:".red(), e);
@@ -13,26 +102,115 @@ pub fn run(details: bool, dirty: bool, locked: bool, json: bool) {
         }
     };
 
-    let options = WorktreeListOptions {
-        dirty,
-        locked,
-        details,
+    let fields = match options.fields.as_deref().map(parse_fields).transpose() {
+        Ok(fields) => fields,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let hidden = match hidden_patterns(&repo, options) {
+        Ok(patterns) => patterns,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
     };
 
-    let worktrees = match list_worktrees(&repo) {
+    if options.jsonl {
+        // stdout is line-buffered, so each worktree is emitted as soon as it's ready.
+        let result = for_each_worktree(&repo, dirty_check(options), |wt| {
+            if !should_include_worktree(&repo, &wt, options, &hidden) {
+                return;
+            }
+            let line = match &fields {
+                Some(fields) => serde_json::to_string(&project_fields(&repo, &wt, fields, options))
+                    .map_err(|e| format!("Failed to serialize JSON: {}", e)),
+                None => format_jsonl_line(&wt),
+            };
+            match line {
+                Ok(line) => println!("{}", line),
+                Err(e) => {
+                    eprintln!("{} {}", "Error:".red(), e);
+                    std::process::exit(1);
+                }
+            }
+        });
+        if let Err(e) = result {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+        return;
+    }
+
+    let worktrees = match list_worktrees_with(&repo, dirty_check(options)) {
         Ok(wts) => wts,
         Err(e) => {
             eprintln!("{} {}", "Error:".red(), e);
             std::process::exit(1);
         }
     };
+    let worktrees = if options.main_only {
+        match main_worktree(&repo, worktrees) {
+            Some(wt) => vec![wt],
+            None => {
+                eprintln!(
+                    "{} No main worktree found. Create one with 'grove add <default-branch>'.",
+                    "Error:".red()
+                );
+                std::process::exit(1);
+            }
+        }
+    } else {
+        worktrees
+    };
+    let worktrees = if options.order_added {
+        sort_by_registration(worktrees)
+    } else {
+        worktrees
+    };
+    let (worktrees, activity) = if options.activity {
+        let activity = time("activity times", || {
+            parallel_map(&worktrees, COLUMN_JOBS, |wt| last_activity(&repo, wt))
+        });
+        sort_by_activity(worktrees, activity)
+    } else {
+        (worktrees, Vec::new())
+    };
+
+    if options.path_only {
+        print!("{}", format_path_only(&repo, &worktrees, options, &hidden));
+        return;
+    }
 
-    if json {
+    if options.count {
+        println!("{}", count_matching(&repo, &worktrees, options, &hidden));
+        return;
+    }
+
+    if options.json {
         let filtered: Vec<&Worktree> = worktrees
             .iter()
-            .filter(|wt| should_include_worktree(wt, &options))
+            .filter(|wt| should_include_worktree(&repo, wt, options, &hidden))
             .collect();
-        match serde_json::to_string_pretty(&filtered) {
+        let total = filtered.len();
+        let filtered = paginate(filtered, options);
+        let value = json_worktrees(&repo, &filtered, fields.as_deref(), options);
+        // A paged array alone can't say how many worktrees matched in total.
+        let value = value.map(|value| {
+            if is_paginated(options) {
+                serde_json::json!({
+                    "total": total,
+                    "offset": options.offset.unwrap_or(0),
+                    "limit": options.limit,
+                    "worktrees": value,
+                })
+            } else {
+                value
+            }
+        });
+        match value.and_then(|value| serde_json::to_string_pretty(&value)) {
             Ok(output) => println!("{}", output),
             Err(e) => {
                 eprintln!("{} Failed to serialize JSON: {}", "Error:".red(), e);
@@ -42,56 +220,766 @@ pub fn run(details: bool, dirty: bool, locked: bool, json: bool) {
         return;
     }
 
+    if let Some(fields) = &fields {
+        let matching: Vec<Worktree> = worktrees
+            .iter()
+            .filter(|wt| should_include_worktree(&repo, wt, options, &hidden))
+            .cloned()
+            .collect();
+        let total = matching.len();
+        let page = paginate(matching, options);
+        // No header: scripts parse the --fields table line by line.
+        let rows = field_rows(&repo, &page, fields, options, &hidden);
+        if total == 0 {
+            println!("{}", "No worktrees found matching the criteria.".yellow());
+        } else {
+            print!("{}", render_table(fields, &rows, options.spacing));
+            if let Some(summary) = page_summary(options, rows.len(), total) {
+                println!("{}", summary.dimmed());
+            }
+        }
+        return;
+    }
+
+    if options.header {
+        println!("{}", context_header(&repo).dimmed());
+    }
     // Show legend
-    println!(
-        "{} {} = clean, {} = dirty",
-        "Legend:".dimmed(),
-        "green".green(),
-        "yellow".yellow()
-    );
-    if details {
-        println!("{}", "Symbols: 🔒 = locked, ⚠ = prunable".dimmed());
+    if options.dirty_check {
+        println!(
+            "{} {} = clean, {} = dirty",
+            "Legend:".dimmed(),
+            "green".green(),
+            "yellow".yellow()
+        );
+    } else {
+        println!("{} dirty status not checked", "Legend:".dimmed());
+    }
+    if options.details {
+        println!(
+            "{}",
+            "Symbols: 🔒 = locked, ⚠ = prunable, ✗ = dangling".dimmed()
+        );
     }
     println!();
 
-    let mut found_any = false;
-    let mut matched_any = false;
+    let changes = if options.dirty_files {
+        time("dirty file counts", || {
+            dirty_file_columns(&worktrees, options.ignore_submodules)
+        })
+    } else {
+        vec![String::new(); worktrees.len()]
+    };
+    let ahead = match options.since.as_deref() {
+        Some(since) => match resolve_revision(&repo, since) {
+            Ok(base) => time("ahead counts", || {
+                ahead_columns(&repo, &worktrees, &base, since)
+            }),
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        },
+        None => vec![String::new(); worktrees.len()],
+    };
+
+    let ahead = if options.activity {
+        merge_columns(activity.iter().map(format_activity).collect(), ahead)
+    } else {
+        ahead
+    };
+
+    let ahead = if options.remote_ahead {
+        merge_columns(
+            ahead,
+            time("unpushed counts", || unpushed_columns(&repo, &worktrees)),
+        )
+    } else {
+        ahead
+    };
 
-    for wt in &worktrees {
-        found_any = true;
-        if !should_include_worktree(wt, &options) {
-            continue;
+    let behind = if options.behind || options.behind_only {
+        let label = match options.base.clone() {
+            Some(base) => base,
+            None => get_default_branch(&repo).unwrap_or_else(|e| {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }),
+        };
+        match resolve_revision(&repo, &label) {
+            Ok(base) => time("behind counts", || behind_counts(&repo, &worktrees, &base)),
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
         }
-        matched_any = true;
-        print_worktree_item(wt, &options);
+        .into_iter()
+        .map(|count| count.map(|count| (count, label.clone())))
+        .collect()
+    } else {
+        vec![None; worktrees.len()]
+    };
+    let ahead = merge_columns(
+        ahead,
+        behind
+            .iter()
+            .map(|count| match count {
+                Some((count, label)) => format!("{} behind {}", count, label),
+                None => String::new(),
+            })
+            .collect(),
+    );
+
+    let matching: Vec<(&Worktree, &String, &String)> = worktrees
+        .iter()
+        .zip(&changes)
+        .zip(&ahead)
+        .zip(&behind)
+        .filter(|(((wt, _), _), behind)| {
+            should_include_worktree(&repo, wt, options, &hidden)
+                && (!options.behind_only || matches!(behind, Some((count, _)) if *count > 0))
+        })
+        .map(|(((wt, changes), ahead), _)| (wt, changes, ahead))
+        .collect();
+    let total = matching.len();
+    let page = paginate(matching, options);
+    let labels: Vec<String> = page
+        .iter()
+        .map(|(wt, _, _)| branch_label(&repo, wt))
+        .collect();
+    let cells: Vec<(String, String, &str)> = page
+        .iter()
+        .zip(&labels)
+        .map(|((wt, changes, _), branch)| {
+            (
+                display_path(&wt.path, options, true),
+                format!("[{}]{}", branch, status_symbols(wt)),
+                changes.as_str(),
+            )
+        })
+        .collect();
+    let layout = item_layout(&cells, options.spacing, terminal_size().unwrap_or(80));
+    for ((wt, changes, ahead), branch) in page.iter().zip(&labels) {
+        print_worktree_item(wt, branch, options, changes, ahead, &layout);
     }
 
-    if !found_any {
+    if worktrees.is_empty() {
         println!("{}", "No worktrees found.".yellow());
-    } else if !matched_any {
+    } else if total == 0 {
         println!("{}", "No worktrees found matching the criteria.".yellow());
+    } else if let Some(summary) = page_summary(options, page.len(), total) {
+        println!("{}", summary.dimmed());
+    }
+
+    let hidden_count = worktrees.iter().filter(|wt| is_hidden(wt, &hidden)).count();
+    if hidden_count > 0 {
+        println!(
+            "{}",
+            format!(
+                "{} worktree(s) hidden by .groveignore. Use --all to show them.",
+                hidden_count
+            )
+            .dimmed()
+        );
     }
 }
 
-fn should_include_worktree(worktree: &Worktree, options: &WorktreeListOptions) -> bool {
+/// Branch globs from `.groveignore`, unless `--all` asked to show everything.
+fn hidden_patterns(
+    repo: &RepoContext,
+    options: &WorktreeListOptions,
+) -> Result<Vec<String>, String> {
+    if options.all {
+        return Ok(Vec::new());
+    }
+    read_ignore_patterns(project_root(repo))
+}
+
+/// `hidden` holds `.groveignore` globs; matching branches are left out of the
+/// listing. This only affects display, never which worktrees prune may touch.
+fn should_include_worktree(
+    repo: &RepoContext,
+    worktree: &Worktree,
+    options: &WorktreeListOptions,
+    hidden: &[String],
+) -> bool {
+    if is_hidden(worktree, hidden) {
+        return false;
+    }
     if options.dirty && !worktree.is_dirty {
         return false;
     }
     if options.locked && !worktree.is_locked {
         return false;
     }
-    true
+    if let Some(pattern) = options.locked_reason.as_deref() {
+        if !lock_reason_matches(worktree, pattern) {
+            return false;
+        }
+    }
+    if options.dangling && !worktree.is_dangling {
+        return false;
+    }
+    if options.remote_ahead && !has_unpushed_commits(repo, worktree) {
+        return false;
+    }
+    if options.external && !is_external(repo, worktree) {
+        return false;
+    }
+    if let Some(duration) = options.newer_than.as_deref() {
+        let threshold_ms = parse_duration(duration).expect("validated by clap");
+        if !created_within(worktree, threshold_ms, Utc::now()) {
+            return false;
+        }
+    }
+    if options.author.is_none() && options.committer.is_none() {
+        return true;
+    }
+
+    // Only read the tip commit when an identity filter needs it.
+    match commit_signature(repo, &worktree.head) {
+        Ok(signature) => matches_identity_filters(&signature, options),
+        Err(_) => false,
+    }
 }
 
-fn print_worktree_item(worktree: &Worktree, options: &WorktreeListOptions) {
-    let display_path = format_path_with_tilde(&worktree.path);
+/// Whether `worktree` is locked with a reason containing `pattern`, ignoring case.
+fn lock_reason_matches(worktree: &Worktree, pattern: &str) -> bool {
+    worktree.is_locked
+        && worktree
+            .lock_reason
+            .as_deref()
+            .is_some_and(|reason| reason.to_lowercase().contains(&pattern.to_lowercase()))
+}
 
-    let branch_display = if worktree.is_dirty {
-        format!("[{}]", worktree.branch).yellow().to_string()
-    } else {
-        format!("[{}]", worktree.branch).green().to_string()
+/// Whether `worktree` was created in the `threshold_ms` before `now`.
+/// Worktrees with an unknown creation time never count as recent.
+fn created_within(worktree: &Worktree, threshold_ms: u64, now: DateTime<Utc>) -> bool {
+    let cutoff = now - chrono::Duration::milliseconds(threshold_ms as i64);
+    worktree.created_at.timestamp() != 0 && worktree.created_at > cutoff
+}
+
+/// Worktrees without an upstream never count as unpushed: there's nothing to
+/// compare against, and `--remote-ahead` is about branches that were pushed.
+fn has_unpushed_commits(repo: &RepoContext, worktree: &Worktree) -> bool {
+    if worktree.branch.is_empty() || worktree.branch == DETACHED_HEAD || worktree.is_dangling {
+        return false;
+    }
+    matches!(unpushed_commits(repo, &worktree.branch), Some(count) if count > 0)
+}
+
+fn is_hidden(worktree: &Worktree, hidden: &[String]) -> bool {
+    hidden
+        .iter()
+        .any(|pattern| branch_glob_matches(pattern, &worktree.branch))
+}
+
+fn matches_identity_filters(signature: &CommitSignature, options: &WorktreeListOptions) -> bool {
+    let matches = |pattern: &Option<String>, name: &str, email: &str| match pattern {
+        Some(pattern) => {
+            let pattern = pattern.to_lowercase();
+            name.to_lowercase().contains(&pattern) || email.to_lowercase().contains(&pattern)
+        }
+        None => true,
     };
 
+    matches(
+        &options.author,
+        &signature.author_name,
+        &signature.author_email,
+    ) && matches(
+        &options.committer,
+        &signature.committer_name,
+        &signature.committer_email,
+    )
+}
+
+/// One absolute path per line with no decoration, for piping into other tools.
+fn format_path_only(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    options: &WorktreeListOptions,
+    hidden: &[String],
+) -> String {
+    let matching = worktrees
+        .iter()
+        .filter(|wt| should_include_worktree(repo, wt, options, hidden))
+        .collect();
+    paginate(matching, options)
+        .into_iter()
+        .map(|wt| format!("{}\n", display_path(&wt.path, options, false)))
+        .collect()
+}
+
+/// A worktree path as shown to people: relative to `--relative-to` when
+/// given (in full if there is no relative form), else in full with the home
+/// directory as `~` when `tilde` is set.
+fn display_path(path: &str, options: &WorktreeListOptions, tilde: bool) -> String {
+    let relative = options
+        .relative_to
+        .as_deref()
+        .and_then(|anchor| relative_path(Path::new(path), anchor));
+    match relative {
+        Some(relative) => relative.to_string_lossy().into_owned(),
+        None if tilde => format_path_with_tilde(path),
+        None => path.to_string(),
+    }
+}
+
+fn is_paginated(options: &WorktreeListOptions) -> bool {
+    options.limit.is_some() || options.offset.is_some()
+}
+
+/// The page of already filtered and sorted `items` selected by `--offset` and `--limit`.
+fn paginate<T>(items: Vec<T>, options: &WorktreeListOptions) -> Vec<T> {
+    items
+        .into_iter()
+        .skip(options.offset.unwrap_or(0))
+        .take(options.limit.unwrap_or(usize::MAX))
+        .collect()
+}
+
+/// e.g. "Showing 11-20 of 153 worktrees", when the listing is paginated.
+fn page_summary(options: &WorktreeListOptions, shown: usize, total: usize) -> Option<String> {
+    if !is_paginated(options) {
+        return None;
+    }
+    let offset = options.offset.unwrap_or(0);
+    Some(if shown == 0 {
+        format!("No worktrees past offset {} ({} total)", offset, total)
+    } else {
+        format!(
+            "Showing {}-{} of {} worktrees",
+            offset + 1,
+            offset + shown,
+            total
+        )
+    })
+}
+
+fn count_matching(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    options: &WorktreeListOptions,
+    hidden: &[String],
+) -> usize {
+    worktrees
+        .iter()
+        .filter(|wt| should_include_worktree(repo, wt, options, hidden))
+        .count()
+}
+
+/// One line orienting the user in a bare-clone layout: where the project
+/// lives, which bare clone backs it, and its default branch.
+fn context_header(repo: &RepoContext) -> String {
+    format!(
+        "Project: {}  Bare clone: {}  Default branch: {}",
+        format_path_with_tilde(&project_root(repo).to_string_lossy()),
+        format_path_with_tilde(&repo_path(repo).to_string_lossy()),
+        get_default_branch(repo).unwrap_or_else(|_| "unknown".to_string())
+    )
+}
+
+fn worktree_upstream(repo: &RepoContext, worktree: &Worktree) -> Option<String> {
+    if worktree.branch.is_empty() || worktree.branch == DETACHED_HEAD {
+        return None;
+    }
+    upstream_branch(repo, &worktree.branch)
+}
+
+fn status_text(repo: &RepoContext, worktree: &Worktree, options: &WorktreeListOptions) -> String {
+    let status = worktree.status_label_with(options.dirty_check.then_some(worktree.is_dirty));
+    if is_external(repo, worktree) {
+        format!("{}, external", status)
+    } else {
+        status
+    }
+}
+
+/// Whether the worktree lives outside the project root. Symlinks are
+/// resolved where the paths still exist.
+fn is_external(repo: &RepoContext, worktree: &Worktree) -> bool {
+    let root = project_root(repo);
+    let canonical_root = std::fs::canonicalize(root).unwrap_or_else(|_| root.to_path_buf());
+    let path = Path::new(&worktree.path);
+    let canonical_path = std::fs::canonicalize(path).unwrap_or_else(|_| path.to_path_buf());
+    !(path.starts_with(root) || canonical_path.starts_with(&canonical_root))
+}
+
+/// `--no-dirty-check` skips `git status` entirely; `--ignore-submodules`
+/// narrows it.
+fn dirty_check(options: &WorktreeListOptions) -> DirtyCheck {
+    if !options.dirty_check {
+        DirtyCheck::Skip
+    } else if options.ignore_submodules {
+        DirtyCheck::IgnoreSubmodules
+    } else {
+        DirtyCheck::Full
+    }
+}
+
+fn field_text(
+    repo: &RepoContext,
+    worktree: &Worktree,
+    field: ListField,
+    options: &WorktreeListOptions,
+) -> String {
+    match field {
+        ListField::Path => display_path(&worktree.path, options, true),
+        ListField::Branch => branch_label(repo, worktree),
+        ListField::Head => worktree.head.chars().take(8).collect(),
+        ListField::Created => created_text(worktree, options),
+        ListField::Status => status_text(repo, worktree, options),
+        ListField::Upstream => worktree_upstream(repo, worktree).unwrap_or_else(|| "-".to_string()),
+        ListField::Size => format_size(directory_size(Path::new(&worktree.path))),
+        ListField::LastCommit => {
+            last_commit_summary(repo, &worktree.head).unwrap_or_else(|_| "-".to_string())
+        }
+    }
+}
+
+/// One row of `--fields` cells for each worktree that passes the filters.
+fn field_rows(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    fields: &[ListField],
+    options: &WorktreeListOptions,
+    hidden: &[String],
+) -> Vec<Vec<String>> {
+    worktrees
+        .iter()
+        .filter(|wt| should_include_worktree(repo, wt, options, hidden))
+        .map(|wt| {
+            fields
+                .iter()
+                .map(|field| field_text(repo, wt, *field, options))
+                .collect()
+        })
+        .collect()
+}
+
+/// Restrict a worktree's JSON to the selected fields.
+fn project_fields(
+    repo: &RepoContext,
+    worktree: &Worktree,
+    fields: &[ListField],
+    options: &WorktreeListOptions,
+) -> serde_json::Value {
+    let mut object = serde_json::Map::new();
+    for field in fields {
+        let value = match field {
+            ListField::Path => serde_json::json!(worktree.path),
+            ListField::Branch => serde_json::json!(worktree.branch),
+            ListField::Head => serde_json::json!(worktree.head),
+            ListField::Created => serde_json::json!(worktree.created_at),
+            ListField::Status => serde_json::json!(status_text(repo, worktree, options)),
+            ListField::Upstream => serde_json::json!(worktree_upstream(repo, worktree)),
+            ListField::Size => serde_json::json!(directory_size(Path::new(&worktree.path))),
+            ListField::LastCommit => {
+                serde_json::json!(last_commit_summary(repo, &worktree.head).ok())
+            }
+        };
+        object.insert(field.json_key().to_string(), value);
+    }
+    serde_json::Value::Object(object)
+}
+
+/// Align rows under an upper-case header derived from the selected fields.
+fn render_table(fields: &[ListField], rows: &[Vec<String>], spacing: TableSpacing) -> String {
+    let header: Vec<String> = fields.iter().map(|f| f.name().to_uppercase()).collect();
+    let widths: Vec<usize> = (0..fields.len())
+        .map(|i| {
+            std::iter::once(&header)
+                .chain(rows)
+                .map(|row| row[i].chars().count())
+                .max()
+                .unwrap_or(0)
+        })
+        .collect();
+
+    let mut output = String::new();
+    for row in std::iter::once(&header).chain(rows) {
+        let cells: Vec<String> = row
+            .iter()
+            .zip(&widths)
+            .map(|(cell, width)| format!("{:<width$}", cell, width = width))
+            .collect();
+        output.push_str(cells.join(spacing.gap()).trim_end());
+        output.push('\n');
+    }
+    output
+}
+
+/// Upstream divergence added to `--json` output by `--ahead-behind`.
+#[derive(Serialize)]
+struct AheadBehind {
+    upstream: String,
+    ahead: usize,
+    behind: usize,
+}
+
+#[derive(Serialize)]
+struct JsonWorktree<'a> {
+    #[serde(flatten)]
+    worktree: &'a Worktree,
+    /// Left out for worktrees without an upstream.
+    #[serde(flatten)]
+    ahead_behind: Option<AheadBehind>,
+}
+
+/// The `--json` array: projected `fields` if given, else whole worktrees with
+/// their upstream divergence when `--ahead-behind` asks for it.
+fn json_worktrees(
+    repo: &RepoContext,
+    worktrees: &[&Worktree],
+    fields: Option<&[ListField]>,
+    options: &WorktreeListOptions,
+) -> Result<serde_json::Value, serde_json::Error> {
+    if let Some(fields) = fields {
+        return Ok(worktrees
+            .iter()
+            .map(|wt| project_fields(repo, wt, fields, options))
+            .collect());
+    }
+    if !options.ahead_behind {
+        return serde_json::to_value(worktrees);
+    }
+    let divergence = time("ahead/behind", || {
+        parallel_map(worktrees, COLUMN_JOBS, |wt| {
+            let status = crate::git::worktree_status(&wt.path).ok()?;
+            Some(AheadBehind {
+                upstream: status.upstream?,
+                ahead: status.ahead,
+                behind: status.behind,
+            })
+        })
+    });
+    let entries: Vec<JsonWorktree> = worktrees
+        .iter()
+        .zip(divergence)
+        .map(|(worktree, ahead_behind)| JsonWorktree {
+            worktree,
+            ahead_behind,
+        })
+        .collect();
+    serde_json::to_value(entries)
+}
+
+fn format_jsonl_line(worktree: &Worktree) -> Result<String, String> {
+    serde_json::to_string(worktree).map_err(|e| format!("Failed to serialize JSON: {}", e))
+}
+
+/// Number of worktrees inspected at the same time for per-worktree columns.
+const COLUMN_JOBS: usize = 4;
+
+/// The `--dirty-files` column for each worktree, e.g. `+3 ~2 ?1`. Clean
+/// worktrees are left blank without running git.
+fn dirty_file_columns(worktrees: &[Worktree], ignore_submodules: bool) -> Vec<String> {
+    parallel_map(worktrees, COLUMN_JOBS, |wt| {
+        if !wt.is_dirty {
+            return String::new();
+        }
+        dirty_file_counts(&wt.path, ignore_submodules)
+            .map(|counts| format_dirty_file_counts(&counts))
+            .unwrap_or_default()
+    })
+}
+
+/// The `--since` column for each worktree, e.g. `3 ahead of origin/release`.
+/// Branches with nothing beyond `base` are left blank.
+fn ahead_columns(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    base: &str,
+    label: &str,
+) -> Vec<String> {
+    parallel_map(worktrees, COLUMN_JOBS, |wt| {
+        if wt.head.is_empty() || wt.is_dangling {
+            return String::new();
+        }
+        match commits_ahead(repo, base, &wt.head) {
+            Ok(0) | Err(_) => String::new(),
+            Ok(count) => format!("{} ahead of {}", count, label),
+        }
+    })
+}
+
+/// The last time a worktree saw any activity: the newest of its tip commit,
+/// when it was last touched on disk, and when it was created.
+fn last_activity(repo: &RepoContext, worktree: &Worktree) -> DateTime<Utc> {
+    let commit = commit_time(repo, &worktree.head).ok();
+    let touched = touched_at(Path::new(&worktree.path));
+    [commit, touched]
+        .into_iter()
+        .flatten()
+        .fold(worktree.created_at, DateTime::max)
+}
+
+/// The primary checkout for `--main-only`: the worktree on the default branch,
+/// or failing that the one on `main` or `master`.
+fn main_worktree(repo: &RepoContext, mut worktrees: Vec<Worktree>) -> Option<Worktree> {
+    let default_branch = get_default_branch(repo).ok();
+    let index = default_branch
+        .and_then(|branch| worktrees.iter().position(|wt| wt.branch == branch))
+        .or_else(|| worktrees.iter().position(|wt| wt.is_main))?;
+    Some(worktrees.swap_remove(index))
+}
+
+/// Most recently active first; ties keep `git worktree list` order.
+fn sort_by_activity(
+    worktrees: Vec<Worktree>,
+    activity: Vec<DateTime<Utc>>,
+) -> (Vec<Worktree>, Vec<DateTime<Utc>>) {
+    let mut rows: Vec<(Worktree, DateTime<Utc>)> = worktrees.into_iter().zip(activity).collect();
+    rows.sort_by(|a, b| b.1.cmp(&a.1));
+    rows.into_iter().unzip()
+}
+
+/// Oldest registration first, for `--order-added`. Worktrees without an admin
+/// directory go last; ties keep `git worktree list` order.
+fn sort_by_registration(worktrees: Vec<Worktree>) -> Vec<Worktree> {
+    let mut rows: Vec<(Option<DateTime<Utc>>, Worktree)> = worktrees
+        .into_iter()
+        .map(|wt| (registered_at(Path::new(&wt.path)), wt))
+        .collect();
+    rows.sort_by_key(|(registered, _)| (registered.is_none(), *registered));
+    rows.into_iter().map(|(_, wt)| wt).collect()
+}
+
+fn format_activity(time: &DateTime<Utc>) -> String {
+    let relative = format_created_time(time);
+    if relative.ends_with(" ago") || relative == "unknown" {
+        format!("active {}", relative)
+    } else {
+        format!("active on {}", relative)
+    }
+}
+
+/// How many commits of `base` each worktree's branch is missing, for
+/// `--behind`. `None` where there's no commit to compare.
+fn behind_counts(repo: &RepoContext, worktrees: &[Worktree], base: &str) -> Vec<Option<usize>> {
+    parallel_map(worktrees, COLUMN_JOBS, |wt| {
+        if wt.head.is_empty() || wt.is_dangling {
+            return None;
+        }
+        commits_ahead(repo, &wt.head, base).ok()
+    })
+}
+
+/// Join two trailing table columns row by row, e.g. `2 ahead of main, 1 behind main`.
+fn merge_columns(first: Vec<String>, second: Vec<String>) -> Vec<String> {
+    first
+        .into_iter()
+        .zip(second)
+        .map(|(a, b)| match (a.is_empty(), b.is_empty()) {
+            (_, true) => a,
+            (true, false) => b,
+            (false, false) => format!("{}, {}", a, b),
+        })
+        .collect()
+}
+
+/// The `--remote-ahead` column for each worktree, e.g. `2 unpushed to origin/feature`.
+fn unpushed_columns(repo: &RepoContext, worktrees: &[Worktree]) -> Vec<String> {
+    parallel_map(worktrees, COLUMN_JOBS, |wt| {
+        if wt.branch.is_empty() || wt.branch == DETACHED_HEAD || wt.is_dangling {
+            return String::new();
+        }
+        let upstream = match upstream_branch(repo, &wt.branch) {
+            Some(upstream) => upstream,
+            None => return String::new(),
+        };
+        match commits_ahead(repo, &upstream, &wt.branch) {
+            Ok(0) | Err(_) => String::new(),
+            Ok(count) => format!("{} unpushed to {}", count, upstream),
+        }
+    })
+}
+
+fn format_dirty_file_counts(counts: &DirtyFileCounts) -> String {
+    [
+        ('+', counts.staged),
+        ('~', counts.unstaged),
+        ('?', counts.untracked),
+    ]
+    .iter()
+    .filter(|(_, count)| *count > 0)
+    .map(|(symbol, count)| format!("{}{}", symbol, count))
+    .collect::<Vec<_>>()
+    .join(" ")
+}
+
+/// When a worktree was created: relative by default, exact with `--created absolute`.
+fn created_text(worktree: &Worktree, options: &WorktreeListOptions) -> String {
+    if options.absolute_created {
+        format_created_timestamp(&worktree.created_at)
+    } else {
+        format_created_time(&worktree.created_at)
+    }
+}
+
+/// The branch, or for a detached worktree the tag it sits at, e.g.
+/// `detached (v2.1.0)`.
+fn branch_label(repo: &RepoContext, worktree: &Worktree) -> String {
+    if worktree.branch != DETACHED_HEAD || worktree.head.is_empty() {
+        return worktree.branch.clone();
+    }
+    match describe_commit(repo, &worktree.head) {
+        Some(tag) => format!("detached ({})", tag),
+        None => worktree.branch.clone(),
+    }
+}
+
+/// Column widths and spacing for the default view.
+#[derive(Debug, PartialEq, Eq)]
+struct ItemLayout {
+    path_width: usize,
+    branch_width: usize,
+    changes_width: usize,
+    gap: &'static str,
+}
+
+/// Normal and wide layouts size the path and branch columns from the
+/// terminal width. Compact sizes each column to its longest cell, still
+/// truncating paths that would take more than half the terminal.
+/// `cells` holds each row's path, branch text, and changes.
+fn item_layout(
+    cells: &[(String, String, &str)],
+    spacing: TableSpacing,
+    terminal_width: usize,
+) -> ItemLayout {
+    let path_width = std::cmp::max(20, terminal_width / 2);
+    let branch_width = std::cmp::max(15, terminal_width * 3 / 10);
+    match spacing {
+        TableSpacing::Compact => ItemLayout {
+            path_width: cells
+                .iter()
+                .map(|(path, _, _)| path.len())
+                .max()
+                .unwrap_or(0)
+                .min(path_width),
+            branch_width: cells
+                .iter()
+                .map(|(_, branch, _)| branch.len())
+                .max()
+                .unwrap_or(0),
+            changes_width: cells
+                .iter()
+                .map(|(_, _, changes)| changes.len())
+                .max()
+                .unwrap_or(0),
+            gap: spacing.gap(),
+        },
+        TableSpacing::Normal | TableSpacing::Wide => ItemLayout {
+            path_width,
+            branch_width,
+            changes_width: 12,
+            gap: spacing.gap(),
+        },
+    }
+}
+
+fn status_symbols(worktree: &Worktree) -> String {
     let mut symbols = String::new();
     if worktree.is_locked {
         symbols.push_str(" 🔒");
@@ -99,13 +987,34 @@ fn print_worktree_item(worktree: &Worktree, options: &WorktreeListOptions) {
     if worktree.is_prunable {
         symbols.push_str(" ⚠");
     }
+    if worktree.is_dangling {
+        symbols.push_str(" ✗");
+    }
+    symbols
+}
 
-    let created_str = format_created_time(&worktree.created_at);
+fn print_worktree_item(
+    worktree: &Worktree,
+    branch: &str,
+    options: &WorktreeListOptions,
+    changes: &str,
+    ahead: &str,
+    layout: &ItemLayout,
+) {
+    let display_path = display_path(&worktree.path, options, true);
 
-    // Calculate widths
-    let terminal_width = terminal_size().unwrap_or(80);
-    let path_width = std::cmp::max(20, terminal_width / 2);
-    let branch_width = std::cmp::max(15, terminal_width * 3 / 10);
+    let branch_display = if !options.dirty_check {
+        format!("[{}]", branch)
+    } else if worktree.is_dirty {
+        format!("[{}]", branch).yellow().to_string()
+    } else {
+        format!("[{}]", branch).green().to_string()
+    };
+
+    let symbols = status_symbols(worktree);
+    let created_str = created_text(worktree, options);
+    let path_width = layout.path_width;
+    let branch_width = layout.branch_width;
 
     let truncated_path = if display_path.len() > path_width {
         format!(
@@ -117,17 +1026,40 @@ fn print_worktree_item(worktree: &Worktree, options: &WorktreeListOptions) {
     };
 
     let path_spacing = " ".repeat(path_width.saturating_sub(truncated_path.len()));
-    let branch_text = format!("[{}]{}", worktree.branch, symbols);
+    let branch_text = format!("[{}]{}", branch, symbols);
     let branch_spacing = " ".repeat(branch_width.saturating_sub(branch_text.len()));
 
+    let changes_column = if options.dirty_files {
+        format!(
+            "{:<width$}{}",
+            changes,
+            layout.gap,
+            width = layout.changes_width
+        )
+        .yellow()
+        .to_string()
+    } else {
+        String::new()
+    };
+
+    let ahead_column = if ahead.is_empty() {
+        String::new()
+    } else {
+        format!("{}{}", layout.gap, ahead.cyan())
+    };
+
     println!(
-        "{}{}  {}{}{}  {}",
+        "{}{}{}{}{}{}{}{}{}{}",
         truncated_path,
         path_spacing,
+        layout.gap,
         branch_display,
         symbols,
         branch_spacing,
-        created_str.dimmed()
+        layout.gap,
+        changes_column,
+        created_str.dimmed(),
+        ahead_column
     );
 
     if options.details {
@@ -154,3 +1086,883 @@ fn terminal_size() -> Option<usize> {
     }
     None
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{
+        create_test_repo, list_worktrees, open_repo, project_root, repo_path, run_test_git,
+        set_fake_git,
+    };
+    use crate::utils::make_temp_dir;
+    use std::fs;
+
+    #[test]
+    fn jsonl_lines_each_parse_as_a_worktree() {
+        let repo = create_test_repo("list-jsonl");
+        repo.add_worktree("main");
+        repo.add_worktree("feature-a");
+        let root = project_root(&repo.context);
+
+        let mut lines = Vec::new();
+        for_each_worktree(&repo.context, DirtyCheck::Full, |wt| {
+            lines.push(format_jsonl_line(&wt).unwrap())
+        })
+        .unwrap();
+
+        assert_eq!(lines.len(), 2);
+        for line in &lines {
+            assert!(!line.contains('\n'));
+            let value: serde_json::Value = serde_json::from_str(line).unwrap();
+            let path = value["path"].as_str().unwrap();
+            assert!(Path::new(path).starts_with(root));
+            assert!(value["branch"].is_string());
+            assert!(value["isDirty"].is_boolean());
+        }
+    }
+
+    #[test]
+    fn json_has_the_same_keys_for_main_and_linked_worktrees() {
+        const KEYS: [&str; 9] = [
+            "path",
+            "branch",
+            "head",
+            "createdAt",
+            "isDirty",
+            "isLocked",
+            "isPrunable",
+            "isMain",
+            "isDangling",
+        ];
+        let repo = create_test_repo("list-json-keys");
+        repo.add_worktree("main");
+        repo.add_worktree("feature-a");
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert!(worktrees.iter().any(|wt| wt.is_main));
+        assert!(worktrees.iter().any(|wt| !wt.is_main));
+        let value = serde_json::to_value(&worktrees).unwrap();
+        for object in value.as_array().unwrap() {
+            let mut keys: Vec<&str> = object
+                .as_object()
+                .unwrap()
+                .keys()
+                .map(String::as_str)
+                .collect();
+            keys.sort_unstable();
+            let mut expected = KEYS.to_vec();
+            expected.sort_unstable();
+            assert_eq!(keys, expected);
+        }
+
+        // Fields that can be unknown are emitted as null rather than left out.
+        let projected = project_fields(
+            &repo.context,
+            &worktrees[0],
+            &ListField::ALL,
+            &identity_options(None, None),
+        );
+        assert_eq!(projected.as_object().unwrap().len(), ListField::ALL.len());
+        assert!(projected["upstream"].is_null());
+    }
+
+    fn identity_options(author: Option<&str>, committer: Option<&str>) -> WorktreeListOptions {
+        WorktreeListOptions {
+            relative_to: None,
+            dirty: false,
+            locked: false,
+            locked_reason: None,
+            dangling: false,
+            all: false,
+            main_only: false,
+            ignore_submodules: false,
+            dirty_check: true,
+            dirty_files: false,
+            since: None,
+            remote_ahead: false,
+            external: false,
+            newer_than: None,
+            activity: false,
+            order_added: false,
+            behind: false,
+            behind_only: false,
+            base: None,
+            author: author.map(str::to_string),
+            committer: committer.map(str::to_string),
+            fields: None,
+            path_only: false,
+            count: false,
+            absolute_created: false,
+            limit: None,
+            offset: None,
+            details: false,
+            header: true,
+            spacing: TableSpacing::Normal,
+            ahead_behind: false,
+            json: false,
+            jsonl: false,
+        }
+    }
+
+    #[test]
+    fn identity_filters_match_tip_commit_author_and_committer() {
+        let repo = create_test_repo("list-committer");
+        repo.add_worktree("feature-ours");
+        let theirs = repo.add_worktree("feature-theirs");
+        run_test_git(
+            &theirs,
+            &[
+                "commit",
+                "-q",
+                "--allow-empty",
+                "-m",
+                "theirs",
+                "--author=Alice Example <Alice@Example.com>",
+            ],
+        );
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let matching = |options: &WorktreeListOptions| -> Vec<String> {
+            let mut branches: Vec<String> = worktrees
+                .iter()
+                .filter(|wt| should_include_worktree(&repo.context, wt, options, &[]))
+                .map(|wt| wt.branch.clone())
+                .collect();
+            branches.sort();
+            branches
+        };
+
+        assert_eq!(
+            matching(&identity_options(Some("alice@example"), None)),
+            vec!["feature-theirs".to_string()]
+        );
+        assert!(matching(&identity_options(None, Some("alice"))).is_empty());
+        assert_eq!(
+            matching(&identity_options(Some("grove test"), Some("GROVE"))),
+            vec!["feature-ours".to_string()]
+        );
+    }
+
+    #[test]
+    fn groveignore_hides_matching_worktrees_unless_all() {
+        let repo = create_test_repo("list-groveignore");
+        repo.add_worktree("feature-a");
+        repo.add_worktree("bot/deps");
+        repo.add_worktree("bot/lint");
+        std::fs::write(
+            project_root(&repo.context).join(".groveignore"),
+            "# tooling worktrees\nbot/*\n",
+        )
+        .unwrap();
+        let worktrees = list_worktrees(&repo.context).unwrap();
+
+        let visible = |options: &WorktreeListOptions| -> Vec<String> {
+            let hidden = hidden_patterns(&repo.context, options).unwrap();
+            let mut branches: Vec<String> = worktrees
+                .iter()
+                .filter(|wt| should_include_worktree(&repo.context, wt, options, &hidden))
+                .map(|wt| wt.branch.clone())
+                .collect();
+            branches.sort();
+            branches
+        };
+
+        let mut options = identity_options(None, None);
+        assert_eq!(visible(&options), vec!["feature-a".to_string()]);
+
+        options.all = true;
+        assert_eq!(
+            visible(&options),
+            vec![
+                "bot/deps".to_string(),
+                "bot/lint".to_string(),
+                "feature-a".to_string()
+            ]
+        );
+    }
+
+    #[test]
+    fn activity_sorts_most_recent_first() {
+        let repo = create_test_repo("list-activity");
+        repo.add_worktree("feature-a");
+        repo.add_worktree("feature-b");
+        repo.add_worktree("feature-c");
+        let now = Utc::now();
+        let mut worktrees = list_worktrees(&repo.context).unwrap();
+        worktrees.retain(|wt| wt.branch.starts_with("feature-"));
+        let activity: Vec<DateTime<Utc>> = worktrees
+            .iter()
+            .map(|wt| match wt.branch.as_str() {
+                "feature-a" => now - chrono::Duration::days(3),
+                "feature-b" => now - chrono::Duration::minutes(5),
+                _ => now - chrono::Duration::hours(2),
+            })
+            .collect();
+
+        let (sorted, activity) = sort_by_activity(worktrees, activity);
+        let order: Vec<&str> = sorted.iter().map(|wt| wt.branch.as_str()).collect();
+        assert_eq!(order, vec!["feature-b", "feature-c", "feature-a"]);
+        assert_eq!(format_activity(&activity[1]), "active 2 hours ago");
+    }
+
+    #[test]
+    fn order_added_sorts_by_registration_order() {
+        let repo = create_test_repo("list-order-added");
+        // Registered out of alphabetical order, so neither name nor
+        // `git worktree list` order can pass for registration order.
+        for branch in ["feature-m", "feature-z", "feature-a"] {
+            let path = repo.add_worktree(branch);
+            // Editing the checkout must not change its place.
+            fs::write(path.join("notes.txt"), "later").unwrap();
+            std::thread::sleep(std::time::Duration::from_millis(20));
+        }
+        let mut worktrees = list_worktrees(&repo.context).unwrap();
+        worktrees.retain(|wt| wt.branch.starts_with("feature-"));
+        worktrees.reverse();
+
+        let sorted = sort_by_registration(worktrees);
+        let order: Vec<&str> = sorted.iter().map(|wt| wt.branch.as_str()).collect();
+        assert_eq!(order, vec!["feature-m", "feature-z", "feature-a"]);
+    }
+
+    #[test]
+    fn last_activity_is_newest_of_commit_touch_and_created() {
+        let repo = create_test_repo("list-activity-metric");
+        let path = repo.add_worktree("feature-a");
+        let mut worktree = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .find(|wt| wt.branch == "feature-a")
+            .unwrap();
+        let commit = commit_time(&repo.context, &worktree.head).unwrap();
+        let touched = touched_at(&path).unwrap();
+
+        worktree.created_at = DateTime::from_timestamp(0, 0).unwrap();
+        assert_eq!(last_activity(&repo.context, &worktree), commit.max(touched));
+
+        let future = Utc::now() + chrono::Duration::days(1);
+        worktree.created_at = future;
+        assert_eq!(last_activity(&repo.context, &worktree), future);
+    }
+
+    #[test]
+    fn count_respects_dirty_filter() {
+        let repo = create_test_repo("list-count");
+        repo.add_worktree("feature-clean");
+        let dirty_a = repo.add_worktree("feature-dirty-a");
+        let dirty_b = repo.add_worktree("feature-dirty-b");
+        std::fs::write(dirty_a.join("scratch.txt"), "?").unwrap();
+        std::fs::write(dirty_b.join("README.md"), "edited").unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let mut options = identity_options(None, None);
+        options.count = true;
+        let all = count_matching(&repo.context, &worktrees, &options, &[]);
+        assert_eq!(all, worktrees.len());
+
+        options.dirty = true;
+        assert_eq!(count_matching(&repo.context, &worktrees, &options, &[]), 2);
+        let hidden = vec!["feature-dirty-b".to_string()];
+        assert_eq!(
+            count_matching(&repo.context, &worktrees, &options, &hidden),
+            1
+        );
+    }
+
+    #[test]
+    fn dirty_files_column_counts_changes_and_leaves_clean_blank() {
+        let repo = create_test_repo("list-dirty-files");
+        repo.add_worktree("feature-clean");
+        let dirty = repo.add_worktree("feature-dirty");
+        std::fs::write(dirty.join("staged.txt"), "s").unwrap();
+        run_test_git(&dirty, &["add", "staged.txt"]);
+        std::fs::write(dirty.join("README.md"), "edited").unwrap();
+        std::fs::write(dirty.join("new-1.txt"), "?").unwrap();
+        std::fs::write(dirty.join("new-2.txt"), "?").unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let columns = dirty_file_columns(&worktrees, false);
+        let column_for = |branch: &str| {
+            let index = worktrees.iter().position(|wt| wt.branch == branch).unwrap();
+            columns[index].clone()
+        };
+
+        assert_eq!(column_for("feature-dirty"), "+1 ~1 ?2");
+        assert_eq!(column_for("feature-clean"), "");
+    }
+
+    #[test]
+    fn since_column_counts_commits_ahead_of_ref() {
+        let repo = create_test_repo("list-since");
+        repo.add_worktree("feature-equal");
+        let ahead = repo.add_worktree("feature-ahead");
+        run_test_git(&ahead, &["commit", "-q", "--allow-empty", "-m", "one"]);
+        run_test_git(&ahead, &["commit", "-q", "--allow-empty", "-m", "two"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let base = resolve_revision(&repo.context, "main").unwrap();
+        let columns = ahead_columns(&repo.context, &worktrees, &base, "main");
+        let column_for = |branch: &str| {
+            let index = worktrees.iter().position(|wt| wt.branch == branch).unwrap();
+            columns[index].clone()
+        };
+
+        assert_eq!(column_for("feature-ahead"), "2 ahead of main");
+        assert_eq!(column_for("feature-equal"), "");
+    }
+
+    #[test]
+    fn main_only_picks_the_default_branch_worktree() {
+        let repo = create_test_repo("list-main-only");
+        repo.add_worktree("feature-a");
+        let main = repo.add_worktree("main");
+        repo.add_worktree("trunk");
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let found = main_worktree(&repo.context, worktrees.clone()).unwrap();
+        assert_eq!(Path::new(&found.path), main);
+
+        // A default branch outside main/master still counts as the primary checkout.
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["symbolic-ref", "HEAD", "refs/heads/trunk"]);
+        let found = main_worktree(&repo.context, worktrees.clone()).unwrap();
+        assert_eq!(found.branch, "trunk");
+
+        let features: Vec<Worktree> = worktrees
+            .into_iter()
+            .filter(|wt| wt.branch == "feature-a")
+            .collect();
+        assert!(main_worktree(&repo.context, features).is_none());
+    }
+
+    #[test]
+    fn header_names_the_project_bare_clone_and_default_branch() {
+        let repo = create_test_repo("list-header");
+        let header = context_header(&repo.context);
+        let project = repo.dir.join("project");
+        assert_eq!(
+            header,
+            format!(
+                "Project: {}  Bare clone: {}  Default branch: main",
+                format_path_with_tilde(&project.to_string_lossy()),
+                format_path_with_tilde(&project.join("project.git").to_string_lossy())
+            )
+        );
+    }
+
+    #[test]
+    fn no_dirty_check_runs_no_git_status() {
+        let dir = make_temp_dir("list-no-dirty-check");
+        let worktree = dir.join("feature-a");
+        fs::create_dir_all(&worktree).unwrap();
+        let script = dir.join("fake-git.sh");
+        let record = dir.join("git-args.txt");
+        fs::write(
+            &script,
+            format!(
+                "#!/bin/sh
+\
+                 echo \"$@\" >> '{}'
+\
+                 [ \"$1 $2\" = \"worktree list\" ] || exit 0
+\
+                 printf 'worktree {}\\nHEAD abc1234567\\nbranch refs/heads/feature-a\\n'
+",
+                record.display(),
+                worktree.display()
+            ),
+        )
+        .unwrap();
+        let repo = open_repo(&dir, &dir);
+        let mut options = identity_options(None, None);
+        options.dirty_check = false;
+
+        set_fake_git(Some(script));
+        let skipped = list_worktrees_with(&repo, dirty_check(&options));
+        let skipped_calls = fs::read_to_string(&record).unwrap();
+        let checked = list_worktrees_with(&repo, DirtyCheck::Full);
+        set_fake_git(None);
+
+        let skipped = skipped.unwrap();
+        assert_eq!(skipped.len(), 1);
+        assert!(!skipped_calls.contains("status"));
+        assert_eq!(status_text(&repo, &skipped[0], &options), "unknown");
+        assert!(checked.is_ok());
+        assert!(fs::read_to_string(&record)
+            .unwrap()
+            .contains("status --porcelain"));
+    }
+
+    #[test]
+    fn field_table_renders_canned_worktrees_from_fake_git() {
+        let dir = make_temp_dir("list-fake-git");
+        let script = dir.join("fake-git.sh");
+        // Under the project root, so neither is marked external.
+        let root = dir.display();
+        fs::write(
+            &script,
+            format!(
+                "#!/bin/sh\n\
+                 [ \"$1 $2\" = \"worktree list\" ] || exit 1\n\
+                 printf 'worktree {root}/feature-a\\nHEAD abc1234567\\nbranch refs/heads/feature-a\\n\\n'\n\
+                 printf 'worktree {root}/feature-b\\nHEAD def4567890\\nbranch refs/heads/feature-b\\nlocked ci\\n'\n"
+            ),
+        )
+        .unwrap();
+        let repo = open_repo(&dir, &dir);
+        let fields = parse_fields("branch,head,status,upstream").unwrap();
+
+        set_fake_git(Some(script));
+        let rows = list_worktrees(&repo).map(|worktrees| {
+            field_rows(
+                &repo,
+                &worktrees,
+                &fields,
+                &identity_options(None, None),
+                &[],
+            )
+        });
+        set_fake_git(None);
+
+        assert_eq!(
+            render_table(&fields, &rows.unwrap(), TableSpacing::Normal),
+            "BRANCH     HEAD      STATUS         UPSTREAM\n\
+             feature-a  abc12345  clean          -\n\
+             feature-b  def45678  clean, locked  -\n"
+        );
+        let _ = fs::remove_dir_all(dir);
+    }
+
+    #[test]
+    fn table_spacing_changes_the_gap_between_columns() {
+        let fields = parse_fields("branch,status").unwrap();
+        let rows = vec![
+            vec!["feature-a".to_string(), "clean".to_string()],
+            vec!["main".to_string(), "dirty".to_string()],
+        ];
+
+        assert_eq!(
+            render_table(&fields, &rows, TableSpacing::Compact),
+            "BRANCH    STATUS\nfeature-a clean\nmain      dirty\n"
+        );
+        assert_eq!(
+            render_table(&fields, &rows, TableSpacing::Normal),
+            "BRANCH     STATUS\nfeature-a  clean\nmain       dirty\n"
+        );
+        assert_eq!(
+            render_table(&fields, &rows, TableSpacing::Wide),
+            "BRANCH       STATUS\nfeature-a    clean\nmain         dirty\n"
+        );
+    }
+
+    #[test]
+    fn compact_layout_fits_columns_to_their_contents() {
+        let cells = vec![
+            ("~/code/project/main".to_string(), "[main]".to_string(), ""),
+            (
+                "~/code/project/feature-a".to_string(),
+                "[feature-a] 🔒".to_string(),
+                "2M 1?",
+            ),
+        ];
+
+        assert_eq!(
+            item_layout(&cells, TableSpacing::Compact, 120),
+            ItemLayout {
+                path_width: 24,
+                branch_width: "[feature-a] 🔒".len(),
+                changes_width: 5,
+                gap: " ",
+            }
+        );
+        let normal = item_layout(&cells, TableSpacing::Normal, 120);
+        assert_eq!((normal.path_width, normal.branch_width), (60, 36));
+        assert_eq!(normal.gap, "  ");
+        let wide = item_layout(&cells, TableSpacing::Wide, 120);
+        assert_eq!((wide.path_width, wide.branch_width), (60, 36));
+        assert_eq!(wide.gap, "    ");
+    }
+
+    #[test]
+    fn compact_layout_still_truncates_long_paths() {
+        let cells = vec![("x".repeat(100), "[main]".to_string(), "")];
+        assert_eq!(
+            item_layout(&cells, TableSpacing::Compact, 80).path_width,
+            40
+        );
+    }
+
+    #[test]
+    fn locked_reason_keeps_only_matching_locked_worktrees() {
+        let repo = create_test_repo("list-locked-reason");
+        let ci = repo.add_worktree("feature-ci");
+        let usb = repo.add_worktree("feature-usb");
+        repo.add_worktree("feature-unlocked");
+        let lock = |path: &std::path::Path, reason: &str| {
+            run_test_git(path, &["worktree", "lock", "--reason", reason, "."])
+        };
+        lock(&ci, "Reserved for CI runs");
+        lock(&usb, "on a USB drive");
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let mut options = identity_options(None, None);
+        options.locked_reason = Some("ci".to_string());
+        let shown: Vec<&str> = worktrees
+            .iter()
+            .filter(|wt| should_include_worktree(&repo.context, wt, &options, &[]))
+            .map(|wt| wt.branch.as_str())
+            .collect();
+        assert_eq!(shown, vec!["feature-ci"]);
+    }
+
+    #[test]
+    fn limit_and_offset_select_a_page_of_matches() {
+        let mut options = identity_options(None, None);
+        let items: Vec<usize> = (1..=7).collect();
+        assert_eq!(paginate(items.clone(), &options), items);
+        assert_eq!(page_summary(&options, 7, 7), None);
+
+        options.limit = Some(3);
+        options.offset = Some(2);
+        assert_eq!(paginate(items.clone(), &options), vec![3, 4, 5]);
+        assert_eq!(
+            page_summary(&options, 3, 7).as_deref(),
+            Some("Showing 3-5 of 7 worktrees")
+        );
+
+        options.offset = Some(6);
+        assert_eq!(paginate(items.clone(), &options), vec![7]);
+        options.offset = Some(9);
+        assert!(paginate(items, &options).is_empty());
+        assert_eq!(
+            page_summary(&options, 0, 7).as_deref(),
+            Some("No worktrees past offset 9 (7 total)")
+        );
+    }
+
+    #[test]
+    fn created_absolute_shows_exact_timestamps() {
+        let repo = create_test_repo("list-created-absolute");
+        repo.add_worktree("feature-a");
+        let mut worktrees = list_worktrees(&repo.context).unwrap();
+        worktrees[0].created_at = DateTime::parse_from_rfc3339("2024-01-02T15:04:05Z")
+            .unwrap()
+            .with_timezone(&Utc);
+        let fields = parse_fields("branch,created").unwrap();
+
+        let mut options = identity_options(None, None);
+        let rows = field_rows(&repo.context, &worktrees, &fields, &options, &[]);
+        assert_eq!(rows[0][1], "2024-01-02");
+
+        options.absolute_created = true;
+        let rows = field_rows(&repo.context, &worktrees, &fields, &options, &[]);
+        assert_eq!(rows[0], vec!["feature-a", "2024-01-02 15:04:05"]);
+    }
+
+    #[test]
+    fn newer_than_keeps_only_recently_created_worktrees() {
+        let repo = create_test_repo("list-newer-than");
+        repo.add_worktree("feature-recent");
+        repo.add_worktree("feature-old");
+        let now = Utc::now();
+        let mut worktrees = list_worktrees(&repo.context).unwrap();
+        for wt in &mut worktrees {
+            wt.created_at = match wt.branch.as_str() {
+                "feature-recent" => now - chrono::Duration::hours(2),
+                "feature-old" => now - chrono::Duration::days(30),
+                _ => DateTime::from_timestamp(0, 0).unwrap(),
+            };
+        }
+
+        let mut options = identity_options(None, None);
+        options.newer_than = Some("7d".to_string());
+        let shown: Vec<&str> = worktrees
+            .iter()
+            .filter(|wt| should_include_worktree(&repo.context, wt, &options, &[]))
+            .map(|wt| wt.branch.as_str())
+            .collect();
+        assert_eq!(shown, vec!["feature-recent"]);
+
+        let mut unknown = worktrees[0].clone();
+        unknown.created_at = DateTime::from_timestamp(0, 0).unwrap();
+        assert!(!created_within(
+            &unknown,
+            parse_duration("1d").unwrap(),
+            now
+        ));
+    }
+
+    #[test]
+    fn detached_worktrees_are_labelled_with_their_tag() {
+        let repo = create_test_repo("list-detached-tag");
+        let main = repo.add_worktree("main");
+        run_test_git(&main, &["tag", "v2.1.0"]);
+        let release = repo.dir.join("project").join("release");
+        let release_path = release.to_string_lossy().into_owned();
+        run_test_git(
+            &main,
+            &["worktree", "add", "-q", "--detach", &release_path, "v2.1.0"],
+        );
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let label = |path: &Path| {
+            let wt = worktrees
+                .iter()
+                .find(|wt| Path::new(&wt.path) == path)
+                .unwrap();
+            branch_label(&repo.context, wt)
+        };
+        assert_eq!(label(&release), "detached (v2.1.0)");
+        assert_eq!(label(&main), "main");
+
+        run_test_git(&release, &["commit", "-q", "--allow-empty", "-m", "next"]);
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let wt = worktrees
+            .iter()
+            .find(|wt| Path::new(&wt.path) == release)
+            .unwrap();
+        assert!(branch_label(&repo.context, wt).starts_with("detached (v2.1.0-1-g"));
+    }
+
+    #[test]
+    fn behind_counts_measure_distance_from_base() {
+        let repo = create_test_repo("list-behind");
+        let main = repo.add_worktree("main");
+        let current = repo.add_worktree("feature-current");
+        repo.add_worktree("feature-three-behind");
+        run_test_git(&main, &["commit", "-q", "--allow-empty", "-m", "one"]);
+        repo.add_worktree("feature-two-behind");
+        run_test_git(&main, &["commit", "-q", "--allow-empty", "-m", "two"]);
+        run_test_git(&main, &["commit", "-q", "--allow-empty", "-m", "three"]);
+        run_test_git(&current, &["merge", "-q", "--ff-only", "main"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let base = resolve_revision(&repo.context, "main").unwrap();
+        let counts = behind_counts(&repo.context, &worktrees, &base);
+        let count_for = |branch: &str| {
+            let index = worktrees.iter().position(|wt| wt.branch == branch).unwrap();
+            counts[index]
+        };
+
+        assert_eq!(count_for("main"), Some(0));
+        assert_eq!(count_for("feature-current"), Some(0));
+        assert_eq!(count_for("feature-two-behind"), Some(2));
+        assert_eq!(count_for("feature-three-behind"), Some(3));
+    }
+
+    #[test]
+    fn merge_columns_joins_non_empty_cells() {
+        let merged = merge_columns(
+            vec!["2 ahead of main".into(), String::new(), String::new()],
+            vec![
+                "1 behind main".into(),
+                "0 behind main".into(),
+                String::new(),
+            ],
+        );
+        assert_eq!(
+            merged,
+            vec!["2 ahead of main, 1 behind main", "0 behind main", ""]
+        );
+    }
+
+    #[test]
+    fn external_worktrees_are_labelled_with_or_without_the_filter() {
+        let repo = create_test_repo("list-external");
+        repo.add_worktree("feature-inside");
+        let outside = repo.dir.join("elsewhere").join("feature-outside");
+        crate::git::add_worktree(
+            &repo.context,
+            &outside.to_string_lossy(),
+            "feature-outside",
+            true,
+            None,
+        )
+        .unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let fields = parse_fields("branch,status").unwrap();
+        let mut options = identity_options(None, None);
+        let mut rows = field_rows(&repo.context, &worktrees, &fields, &options, &[]);
+        rows.sort();
+        assert_eq!(
+            rows,
+            vec![
+                vec!["feature-inside".to_string(), "clean".to_string()],
+                vec!["feature-outside".to_string(), "clean, external".to_string()],
+            ]
+        );
+
+        options.external = true;
+        let shown: Vec<Worktree> = worktrees
+            .iter()
+            .filter(|wt| should_include_worktree(&repo.context, wt, &options, &[]))
+            .cloned()
+            .collect();
+        let branches: Vec<&str> = shown.iter().map(|wt| wt.branch.as_str()).collect();
+        assert_eq!(branches, vec!["feature-outside"]);
+
+        let rows = field_rows(&repo.context, &shown, &fields, &options, &[]);
+        assert_eq!(
+            rows,
+            vec![vec![
+                "feature-outside".to_string(),
+                "clean, external".to_string()
+            ]]
+        );
+    }
+
+    #[test]
+    fn remote_ahead_shows_only_branches_with_unpushed_commits() {
+        let repo = create_test_repo("list-remote-ahead");
+        let ahead = repo.add_worktree("feature-ahead");
+        run_test_git(&ahead, &["push", "-q", "-u", "origin", "feature-ahead"]);
+        run_test_git(&ahead, &["commit", "-q", "--allow-empty", "-m", "local"]);
+        let synced = repo.add_worktree("feature-synced");
+        run_test_git(&synced, &["push", "-q", "-u", "origin", "feature-synced"]);
+        let local = repo.add_worktree("feature-local");
+        run_test_git(&local, &["commit", "-q", "--allow-empty", "-m", "local"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let mut options = identity_options(None, None);
+        options.remote_ahead = true;
+        let shown: Vec<&str> = worktrees
+            .iter()
+            .filter(|wt| should_include_worktree(&repo.context, wt, &options, &[]))
+            .map(|wt| wt.branch.as_str())
+            .collect();
+        assert_eq!(shown, vec!["feature-ahead"]);
+
+        let columns = unpushed_columns(&repo.context, &worktrees);
+        let index = worktrees
+            .iter()
+            .position(|wt| wt.branch == "feature-ahead")
+            .unwrap();
+        assert_eq!(columns[index], "1 unpushed to origin/feature-ahead");
+    }
+
+    #[test]
+    fn ahead_behind_fields_appear_only_when_requested() {
+        let repo = create_test_repo("list-ahead-behind");
+        let ahead = repo.add_worktree("feature-ahead");
+        run_test_git(&ahead, &["push", "-q", "-u", "origin", "feature-ahead"]);
+        run_test_git(&ahead, &["commit", "-q", "--allow-empty", "-m", "local"]);
+        repo.add_worktree("feature-local");
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let worktrees: Vec<&Worktree> = worktrees.iter().collect();
+        let entry = |value: &serde_json::Value, branch: &str| {
+            value
+                .as_array()
+                .unwrap()
+                .iter()
+                .find(|object| object["branch"] == branch)
+                .unwrap()
+                .clone()
+        };
+
+        let mut options = identity_options(None, None);
+        let value = json_worktrees(&repo.context, &worktrees, None, &options).unwrap();
+        assert!(entry(&value, "feature-ahead").get("ahead").is_none());
+        assert!(entry(&value, "feature-ahead").get("upstream").is_none());
+
+        options.ahead_behind = true;
+        let value = json_worktrees(&repo.context, &worktrees, None, &options).unwrap();
+        let tracked = entry(&value, "feature-ahead");
+        assert_eq!(tracked["upstream"], "origin/feature-ahead");
+        assert_eq!(tracked["ahead"], 1);
+        assert_eq!(tracked["behind"], 0);
+        assert!(entry(&value, "feature-local").get("ahead").is_none());
+    }
+
+    #[test]
+    fn fields_render_in_requested_order() {
+        let repo = create_test_repo("list-fields");
+        repo.add_worktree("feature-a");
+        let worktrees = list_worktrees(&repo.context).unwrap();
+
+        let fields = parse_fields("status, branch").unwrap();
+        assert_eq!(fields, vec![ListField::Status, ListField::Branch]);
+        let rows = field_rows(
+            &repo.context,
+            &worktrees,
+            &fields,
+            &identity_options(None, None),
+            &[],
+        );
+
+        assert_eq!(
+            render_table(&fields, &rows, TableSpacing::Normal),
+            "STATUS  BRANCH\nclean   feature-a\n"
+        );
+
+        let projected = project_fields(
+            &repo.context,
+            &worktrees[0],
+            &fields,
+            &identity_options(None, None),
+        );
+        let keys: Vec<&String> = projected.as_object().unwrap().keys().collect();
+        assert_eq!(keys.len(), 2);
+        assert_eq!(projected["branch"], "feature-a");
+    }
+
+    #[test]
+    fn invalid_field_lists_valid_options() {
+        let err = parse_fields("path,colour").unwrap_err();
+        assert!(err.contains("'colour'"));
+        assert!(err.contains("path, branch, head, created, status, upstream, size, last-commit"));
+    }
+
+    #[test]
+    fn path_only_prints_filtered_paths_one_per_line() {
+        let repo = create_test_repo("list-path-only");
+        let clean = repo.add_worktree("feature-clean");
+        let dirty = repo.add_worktree("feature-dirty");
+        std::fs::write(dirty.join("wip.txt"), "wip").unwrap();
+        let worktrees = list_worktrees(&repo.context).unwrap();
+
+        let mut options = identity_options(None, None);
+        options.path_only = true;
+        let all = format_path_only(&repo.context, &worktrees, &options, &[]);
+        let mut lines: Vec<&str> = all.lines().collect();
+        lines.sort();
+        let mut expected = vec![
+            clean.to_string_lossy().to_string(),
+            dirty.to_string_lossy().to_string(),
+        ];
+        expected.sort();
+        assert_eq!(lines, expected);
+
+        options.dirty = true;
+        let filtered = format_path_only(&repo.context, &worktrees, &options, &[]);
+        assert_eq!(filtered, format!("{}\n", dirty.to_string_lossy()));
+    }
+
+    #[test]
+    fn relative_to_renders_paths_from_the_anchor() {
+        let repo = create_test_repo("list-relative-to");
+        let feature = repo.add_worktree("feature-a");
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let project = project_root(&repo.context).to_path_buf();
+
+        let mut options = identity_options(None, None);
+        options.relative_to = Some(project.clone());
+        options.path_only = true;
+        assert_eq!(
+            format_path_only(&repo.context, &worktrees, &options, &[]),
+            "feature-a\n"
+        );
+
+        options.relative_to = Some(project.join("project.git"));
+        assert_eq!(
+            field_text(&repo.context, &worktrees[0], ListField::Path, &options),
+            Path::new("..").join("feature-a").to_string_lossy()
+        );
+        options.relative_to = Some(feature.clone());
+        assert_eq!(
+            display_path(&feature.to_string_lossy(), &options, true),
+            "."
+        );
+    }
+}
@@ -1,10 +1,23 @@
 pub mod add;
+pub mod config;
+pub mod doctor;
+pub mod export;
+pub mod gc;
 pub mod go;
+pub mod hooks;
+pub mod info;
 pub mod init;
 pub mod list;
+pub mod mv_branch;
 pub mod pr;
 pub mod prune;
+pub mod rebase;
+pub mod relocate_root;
 pub mod remove;
+pub mod reset;
 pub mod self_update;
 pub mod shell_init;
+pub mod status;
 pub mod sync;
+pub mod uninit;
+pub mod worktree_root;
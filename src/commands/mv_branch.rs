@@ -0,0 +1,139 @@
+use colored::Colorize;
+use std::path::Path;
+
+use crate::git::{
+    branch_exists, checkout_branch, discover_repo, list_worktrees, resolve_worktree, RepoContext,
+};
+use crate::models::Worktree;
+use crate::utils::trim_trailing_branch_slashes;
+
+pub fn run(name: &str, branch: &str) {
+    let branch = trim_trailing_branch_slashes(branch);
+
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktrees = match list_worktrees(&repo) {
+        Ok(wts) => wts,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktree = match resolve_worktree(&worktrees, trim_trailing_branch_slashes(name)) {
+        Ok(wt) => wt,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    let previous_branch = worktree.branch.clone();
+
+    if let Err(e) = switch_branch(&repo, &worktrees, worktree, branch) {
+        eprintln!("{} {}", "Error:".red(), e);
+        std::process::exit(1);
+    }
+
+    println!(
+        "{} {} → {}",
+        "✓ Switched worktree branch:".green(),
+        previous_branch,
+        branch.bold()
+    );
+    println!("{}", format!("Path: {}", worktree.path).dimmed());
+}
+
+/// Check out an existing branch in `worktree`, refusing when local changes could
+/// be carried over or lost, or when git would reject the checkout.
+fn switch_branch(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    worktree: &Worktree,
+    branch: &str,
+) -> Result<(), String> {
+    if branch.is_empty() {
+        return Err("Branch name is required".to_string());
+    }
+    if worktree.branch == branch {
+        return Err(format!("Worktree is already on branch '{}'", branch));
+    }
+    if worktree.is_dirty {
+        return Err(format!(
+            "Worktree {} has uncommitted changes. Commit or stash them before switching branches.",
+            worktree.path
+        ));
+    }
+    if !branch_exists(repo, branch) {
+        return Err(format!(
+            "Branch '{}' does not exist. Use 'grove add' to create a new branch.",
+            branch
+        ));
+    }
+    // git refuses to check out a branch in two worktrees at once.
+    if let Some(other) = worktrees
+        .iter()
+        .find(|wt| wt.branch == branch && Path::new(&wt.path) != Path::new(&worktree.path))
+    {
+        return Err(format!(
+            "Branch '{}' is already checked out in worktree {}. Use 'grove go {}' instead.",
+            branch, other.path, branch
+        ));
+    }
+
+    checkout_branch(repo, &worktree.path, branch)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, repo_path, run_test_git};
+    use std::fs;
+
+    #[test]
+    fn switches_clean_worktree_to_existing_branch() {
+        let repo = create_test_repo("mv-branch");
+        let path = repo.add_worktree("feature-a");
+        run_test_git(repo_path(&repo.context), &["branch", "feature-b", "main"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let worktree = resolve_worktree(&worktrees, "feature-a").unwrap();
+        switch_branch(&repo.context, &worktrees, worktree, "feature-b").unwrap();
+
+        let head = run_test_git(&path, &["rev-parse", "--abbrev-ref", "HEAD"]);
+        assert_eq!(head.trim(), "feature-b");
+        let relisted = list_worktrees(&repo.context).unwrap();
+        assert!(relisted.iter().any(|wt| wt.branch == "feature-b"));
+        assert!(!relisted.iter().any(|wt| wt.branch == "feature-a"));
+    }
+
+    #[test]
+    fn refuses_branch_checked_out_elsewhere() {
+        let repo = create_test_repo("mv-branch-taken");
+        repo.add_worktree("feature-a");
+        repo.add_worktree("feature-b");
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let worktree = resolve_worktree(&worktrees, "feature-a").unwrap();
+        let err = switch_branch(&repo.context, &worktrees, worktree, "feature-b").unwrap_err();
+        assert!(err.contains("already checked out"));
+    }
+
+    #[test]
+    fn refuses_dirty_worktree() {
+        let repo = create_test_repo("mv-branch-dirty");
+        let path = repo.add_worktree("feature-a");
+        run_test_git(repo_path(&repo.context), &["branch", "feature-b", "main"]);
+        fs::write(path.join("wip.txt"), "wip").unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let worktree = resolve_worktree(&worktrees, "feature-a").unwrap();
+        let err = switch_branch(&repo.context, &worktrees, worktree, "feature-b").unwrap_err();
+        assert!(err.contains("uncommitted changes"));
+    }
+}
@@ -1,7 +1,11 @@
 use colored::Colorize;
 use std::process::Command;
 
-use crate::git::{add_worktree, discover_repo, find_worktree_by_name, project_root, repo_path};
+use crate::git::{
+    add_worktree, discover_repo, get_worktree_by, project_root, repo_path, MatchBy, RepoContext,
+    WorktreeLookupError,
+};
+use crate::models::Worktree;
 
 pub fn run(pr_num: u64) {
     // Check gh CLI is available
@@ -86,13 +90,20 @@ pub fn run(pr_num: u64) {
     let worktree_path_str = worktree_path.to_string_lossy().to_string();
 
     // Check if worktree already exists
-    if let Ok(Some(_)) = find_worktree_by_name(&repo, &worktree_name) {
-        println!(
-            "{} {}",
-            "⚠ Worktree already exists:".yellow(),
-            worktree_path_str.bold()
-        );
-        return;
+    match existing_worktree(&repo, &worktree_path_str) {
+        Ok(Some(_)) => {
+            println!(
+                "{} {}",
+                "⚠ Worktree already exists:".yellow(),
+                worktree_path_str.bold()
+            );
+            return;
+        }
+        Ok(None) => {}
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
     }
 
     // Fetch PR branch
@@ -160,3 +171,36 @@ pub fn run(pr_num: u64) {
     println!("{}", "To switch to this worktree, run:".dimmed());
     println!("  {}", format!("grove go {}", worktree_name).cyan());
 }
+
+/// The worktree registered at exactly `path`, if any. Unlike a name lookup,
+/// `pr-1-fix` never matches `pr-1-fixes`.
+fn existing_worktree(
+    repo: &RepoContext,
+    path: &str,
+) -> Result<Option<Worktree>, WorktreeLookupError> {
+    match get_worktree_by(repo, path, MatchBy::Path) {
+        Ok(worktree) => Ok(Some(worktree)),
+        Err(WorktreeLookupError::NotFound(_)) => Ok(None),
+        Err(e) => Err(e),
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::create_test_repo;
+
+    #[test]
+    fn existing_worktree_requires_an_exact_path() {
+        let repo = create_test_repo("pr-existing");
+        let wanted = project_root(&repo.context).join("pr-1-fix");
+        let wanted = wanted.to_string_lossy();
+        repo.add_worktree("pr-1-fixes");
+
+        assert!(existing_worktree(&repo.context, &wanted).unwrap().is_none());
+
+        let path = repo.add_worktree("pr-1-fix");
+        let found = existing_worktree(&repo.context, &wanted).unwrap().unwrap();
+        assert_eq!(found.path, path.to_string_lossy());
+    }
+}
@@ -1,15 +1,29 @@
-use chrono::Utc;
+use chrono::{DateTime, Utc};
 use colored::Colorize;
+use serde::Serialize;
+use std::fs::OpenOptions;
+use std::io::Write;
+use std::path::Path;
 
 use crate::git::{
-    discover_repo, get_default_branch, is_branch_merged, list_worktrees, remove_worktrees,
-    DETACHED_HEAD,
+    commit_time, current_branch, delete_branch, discover_repo, get_default_branch,
+    is_branch_merged, list_worktrees, remove_worktrees, remove_worktrees_parallel,
+    unpushed_commits, RepoContext, DETACHED_HEAD,
 };
-use crate::models::Worktree;
-use crate::utils::{parse_duration, trim_trailing_branch_slashes};
+use crate::models::{PruneOptions, Worktree};
+use crate::progress::Progress;
+use crate::timing::time;
+use crate::utils::{
+    directory_size, format_created_timestamp, format_size, parallel_map, parse_duration,
+    trim_trailing_branch_slashes,
+};
+
+pub fn run(options: &PruneOptions) {
+    let dry_run = options.dry_run || options.report_only;
+    let force = options.force;
+    let older_than = options.older_than.as_deref();
 
-pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&str>) {
-    if older_than.is_some() && base.is_some() {
+    if older_than.is_some() && !options.base_branches.is_empty() {
         eprintln!(
             "{} --base and --older-than cannot be used together (--base is ignored when --older-than is specified)",
             "Error:".red()
@@ -20,8 +34,11 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
     // Parse the older-than duration if provided
     let age_threshold_ms =
         older_than.map(|duration_str| parse_duration(duration_str).expect("validated by clap"));
+    let since_last_commit = options.since_last_commit.as_deref();
+    let inactivity_threshold_ms = since_last_commit
+        .map(|duration_str| parse_duration(duration_str).expect("validated by clap"));
 
-    let repo = match discover_repo() {
+    let repo = match time("repo discovery", discover_repo) {
         Ok(m) => m,
         Err(e) => {
             eprintln!("{} {}", "Error:".red(), e);
@@ -29,26 +46,30 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
         }
     };
 
-    // Get the base branch
-    let base_branch = if older_than.is_none() {
-        if let Some(b) = base {
-            let normalized = trim_trailing_branch_slashes(b);
-            if normalized.is_empty() {
+    // Get the base branches; a branch merged into any of them counts as merged
+    let base_branches: Vec<String> = if older_than.is_some() {
+        Vec::new()
+    } else if options.base_branches.is_empty() {
+        match get_default_branch(&repo) {
+            Ok(b) => vec![b],
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+    } else {
+        let mut normalized = Vec::new();
+        for base in &options.base_branches {
+            let base = trim_trailing_branch_slashes(base);
+            if base.is_empty() {
                 eprintln!("{} Branch name is required", "Error:".red());
                 std::process::exit(1);
             }
-            normalized.to_string()
-        } else {
-            match get_default_branch(&repo) {
-                Ok(b) => b,
-                Err(e) => {
-                    eprintln!("{} {}", "Error:".red(), e);
-                    std::process::exit(1);
-                }
+            if !normalized.iter().any(|b| b == base) {
+                normalized.push(base.to_string());
             }
         }
-    } else {
-        String::new()
+        normalized
     };
 
     let worktrees = match list_worktrees(&repo) {
@@ -59,39 +80,49 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
         }
     };
 
-    let mut candidates: Vec<Worktree> = Vec::new();
-
-    for wt in &worktrees {
-        if wt.is_main || wt.is_locked || wt.branch == DETACHED_HEAD {
-            continue;
+    let candidates: Vec<Worktree> = if let Some(threshold_ms) = age_threshold_ms {
+        let (kept, unpushed) = age_candidates(&repo, &worktrees, threshold_ms, options, Utc::now());
+        for (wt, count) in &unpushed {
+            eprintln!(
+                "{} Skipping {}: branch '{}' has {} unpushed commit(s). Use --force to prune it anyway.",
+                "Warning:".yellow(),
+                wt.path,
+                wt.branch,
+                count
+            );
         }
-        if !base_branch.is_empty() && wt.branch == base_branch {
-            continue;
+        kept
+    } else {
+        let merged = select_merged_candidates(&repo, &worktrees, &base_branches, dry_run);
+        match inactivity_threshold_ms {
+            Some(threshold_ms) => {
+                select_inactive_candidates(&repo, merged, threshold_ms, Utc::now())
+            }
+            None => merged,
         }
+    };
 
-        if let Some(threshold_ms) = age_threshold_ms {
-            let cutoff = Utc::now() - chrono::Duration::milliseconds(threshold_ms as i64);
-            if wt.created_at.timestamp() == 0 || wt.created_at > cutoff {
-                continue;
-            }
-            candidates.push(wt.clone());
-        } else {
-            match is_branch_merged(&repo, &wt.branch, &base_branch) {
-                Ok(true) => candidates.push(wt.clone()),
-                Ok(false) => {}
-                Err(e) => {
-                    if !dry_run {
-                        eprintln!(
-                            "{} Could not check merge status for branch '{}': {}",
-                            "Warning:".yellow(),
-                            wt.branch,
-                            e
-                        );
-                    }
-                }
+    let candidates = match options.min_age.as_deref() {
+        Some(min_age) if !force => {
+            let threshold_ms = parse_duration(min_age).expect("validated by clap");
+            let (kept, young) = skip_young_candidates(candidates, threshold_ms, Utc::now());
+            for wt in &young {
+                let reason = if wt.created_at.timestamp() == 0 {
+                    "creation time unknown".to_string()
+                } else {
+                    format!("created less than {} ago", min_age)
+                };
+                eprintln!(
+                    "{} Skipping {}: {} (--min-age). Use --force to prune it anyway.",
+                    "Warning:".yellow(),
+                    wt.path,
+                    reason
+                );
             }
+            kept
         }
-    }
+        _ => candidates,
+    };
 
     if candidates.is_empty() {
         if older_than.is_some() {
@@ -99,6 +130,15 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
                 "{}",
                 "No worktrees found older than the specified duration.".yellow()
             );
+        } else if let Some(duration) = since_last_commit {
+            println!(
+                "{}",
+                format!(
+                    "No worktrees found with merged branches and no commits in {}.",
+                    duration
+                )
+                .yellow()
+            );
         } else {
             println!("{}", "No worktrees found with merged branches.".yellow());
         }
@@ -115,6 +155,16 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
             )
             .green()
         );
+    } else if let Some(duration) = since_last_commit {
+        println!(
+            "{}",
+            format!(
+                "Found {} worktree(s) with merged branches and no commits in {}:",
+                candidates.len(),
+                duration
+            )
+            .green()
+        );
     } else {
         println!(
             "{}",
@@ -127,20 +177,42 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
     }
     println!();
 
-    for wt in &candidates {
+    let sizes = if options.size {
+        time("candidate sizes", || candidate_sizes(&candidates))
+    } else {
+        Vec::new()
+    };
+
+    for (index, wt) in candidates.iter().enumerate() {
         println!("  {}", wt.path.bold());
         println!("    {}", format!("Branch: {}", wt.branch).dimmed());
         let status = get_worktree_status(wt);
         println!("    {}", format!("Status: {}", status).dimmed());
         if wt.created_at.timestamp() != 0 {
-            println!(
-                "    {}",
-                format!("Created: {}", wt.created_at.format("%Y-%m-%d")).dimmed()
-            );
+            let created = if options.absolute_created {
+                format_created_timestamp(&wt.created_at)
+            } else {
+                wt.created_at.format("%Y-%m-%d").to_string()
+            };
+            println!("    {}", format!("Created: {}", created).dimmed());
+        }
+        if let Some(size) = sizes.get(index) {
+            println!("    {}", format!("Size: {}", format_size(*size)).dimmed());
         }
         println!();
     }
 
+    let reclaimed = reclaimable_total(&sizes);
+    if let Some(reclaimed) = reclaimed.as_deref() {
+        println!("{}", format!("Total reclaimable: {}", reclaimed).bold());
+        println!();
+    }
+
+    if options.report_only {
+        println!("{}", "Nothing was removed (--report-only).".blue());
+        std::process::exit(report_exit_code(&candidates));
+    }
+
     if dry_run {
         println!(
             "{}",
@@ -149,17 +221,21 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
         return;
     }
 
-    if !force {
+    if !force && !options.force_dirty {
         let dirty_count = candidates.iter().filter(|wt| wt.is_dirty).count();
+        let count = match reclaimed.as_deref() {
+            Some(reclaimed) => format!("{} worktree(s), freeing {}", candidates.len(), reclaimed),
+            None => format!("{} worktree(s)", candidates.len()),
+        };
         let msg = if dirty_count > 0 {
             format!(
-                "Remove {} worktree(s)? {} {} uncommitted changes that will be lost.",
-                candidates.len(),
+                "Remove {}? {} {} uncommitted changes that will be lost.",
+                count,
                 dirty_count,
                 if dirty_count == 1 { "has" } else { "have" }
             )
         } else {
-            format!("Remove {} worktree(s)?", candidates.len())
+            format!("Remove {}?", count)
         };
 
         if !dialoguer::Confirm::new()
@@ -175,7 +251,12 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
 
     println!("{}", "\nRemoving worktrees...".blue());
 
-    let (removed, failed) = remove_worktrees(&repo, &candidates, true);
+    let mut progress = Progress::stderr(candidates.len(), false);
+    let on_start = |wt: &Worktree| progress.step("removing", &progress_label(wt));
+    let (removed, failed) = time("worktree removal", || match options.parallel {
+        Some(jobs) => remove_worktrees_parallel(&repo, &candidates, true, jobs, on_start),
+        None => remove_worktrees(&repo, &candidates, true, on_start),
+    });
 
     for path in &removed {
         println!("{}", format!("✓ Removed worktree: {}", path).green());
@@ -188,6 +269,17 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
         );
     }
 
+    if let Some(log_path) = options.log.as_deref() {
+        let reason = prune_reason(&base_branches, older_than, since_last_commit);
+        let pruned: Vec<&Worktree> = candidates
+            .iter()
+            .filter(|wt| removed.contains(&wt.path))
+            .collect();
+        if let Err(e) = append_prune_log(Path::new(log_path), &pruned, &reason, Utc::now()) {
+            eprintln!("{} {}", "Warning:".yellow(), e);
+        }
+    }
+
     if !removed.is_empty() {
         println!(
             "{}",
@@ -205,6 +297,308 @@ pub fn run(dry_run: bool, force: bool, base: Option<&str>, older_than: Option<&s
             format!("\nFailed to remove {} worktree(s).", failed.len()).yellow()
         );
     }
+
+    if options.remove_branch && !removed.is_empty() {
+        // Branches of age-pruned worktrees were never checked for merge status.
+        if older_than.is_some() && !force {
+            println!(
+                "{}",
+                "\nKept branches of age-pruned worktrees. Use --force with --remove-branch to delete them."
+                    .yellow()
+            );
+            return;
+        }
+
+        let protected_branches = if base_branches.is_empty() {
+            get_default_branch(&repo).into_iter().collect()
+        } else {
+            base_branches
+        };
+        let pruned: Vec<Worktree> = candidates
+            .into_iter()
+            .filter(|wt| removed.contains(&wt.path))
+            .collect();
+        let branches = branches_to_delete(
+            &pruned,
+            &protected_branches,
+            current_branch(&repo).as_deref(),
+        );
+        for (branch, result) in delete_branches(&repo, &branches) {
+            match result {
+                Ok(()) => println!("{}", format!("✓ Deleted branch: {}", branch).green()),
+                Err(e) => println!("{}", format!("✗ {}", e).red()),
+            }
+        }
+    }
+}
+
+/// The `--report-only` exit status: 1 when anything could be pruned, so CI
+/// checks fail until it is cleaned up.
+fn report_exit_code(candidates: &[Worktree]) -> i32 {
+    if candidates.is_empty() {
+        0
+    } else {
+        1
+    }
+}
+
+/// Worktrees whose branch is merged into any of `base_branches`.
+fn select_merged_candidates(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    base_branches: &[String],
+    dry_run: bool,
+) -> Vec<Worktree> {
+    let mut merged = Vec::new();
+    for wt in worktrees {
+        // Merge detection is meaningless for detached worktrees.
+        if is_protected(wt, base_branches, false) {
+            continue;
+        }
+        match merged_into_any(repo, &wt.branch, base_branches) {
+            Ok(true) => merged.push(wt.clone()),
+            Ok(false) => {}
+            Err(e) => {
+                if !dry_run {
+                    eprintln!(
+                        "{} Could not check merge status for branch '{}': {}",
+                        "Warning:".yellow(),
+                        wt.branch,
+                        e
+                    );
+                }
+            }
+        }
+    }
+    merged
+}
+
+/// A merge into one base is enough. Errors only matter when no base reports a merge.
+fn merged_into_any(
+    repo: &RepoContext,
+    branch: &str,
+    base_branches: &[String],
+) -> Result<bool, String> {
+    let mut error = None;
+    for base in base_branches {
+        match is_branch_merged(repo, branch, base) {
+            Ok(true) => return Ok(true),
+            Ok(false) => {}
+            Err(e) => {
+                error.get_or_insert(e);
+            }
+        }
+    }
+    error.map_or(Ok(false), Err)
+}
+
+/// Disk usage of each candidate's directory, in bytes, in candidate order.
+fn candidate_sizes(candidates: &[Worktree]) -> Vec<u64> {
+    parallel_map(candidates, SIZE_JOBS, |wt| {
+        directory_size(Path::new(&wt.path))
+    })
+}
+
+/// The space freed by removing every candidate, e.g. `1.5 MB`, or `None` when
+/// sizes weren't computed.
+fn reclaimable_total(sizes: &[u64]) -> Option<String> {
+    (!sizes.is_empty()).then(|| format_size(sizes.iter().sum()))
+}
+
+/// Directory walks are disk-bound; a few at a time is enough.
+const SIZE_JOBS: usize = 4;
+
+#[derive(Debug, Serialize)]
+struct PruneLogEntry<'a> {
+    path: &'a str,
+    branch: &'a str,
+    head: &'a str,
+    timestamp: String,
+    reason: &'a str,
+}
+
+/// Why the worktrees in this run were selected, as recorded in the prune log.
+fn prune_reason(
+    base_branches: &[String],
+    older_than: Option<&str>,
+    since_last_commit: Option<&str>,
+) -> String {
+    let base_branch = base_branches.join(" or ");
+    match (older_than, since_last_commit) {
+        (Some(duration), _) => format!("older than {}", duration),
+        (None, Some(duration)) => {
+            format!("merged into {}, no commits in {}", base_branch, duration)
+        }
+        (None, None) => format!("merged into {}", base_branch),
+    }
+}
+
+/// Append one JSON line per removed worktree to `log_path`, creating the file
+/// if needed. The file is flushed before returning so the record survives a crash.
+fn append_prune_log(
+    log_path: &Path,
+    removed: &[&Worktree],
+    reason: &str,
+    now: DateTime<Utc>,
+) -> Result<(), String> {
+    let log_error =
+        |e: std::io::Error| format!("Failed to write prune log {}: {}", log_path.display(), e);
+    let mut file = OpenOptions::new()
+        .create(true)
+        .append(true)
+        .open(log_path)
+        .map_err(log_error)?;
+
+    let timestamp = now.to_rfc3339_opts(chrono::SecondsFormat::Secs, true);
+    let mut lines = String::new();
+    for wt in removed {
+        let entry = PruneLogEntry {
+            path: &wt.path,
+            branch: &wt.branch,
+            head: &wt.head,
+            timestamp: timestamp.clone(),
+            reason,
+        };
+        lines.push_str(&serde_json::to_string(&entry).map_err(|e| e.to_string())?);
+        lines.push('\n');
+    }
+
+    file.write_all(lines.as_bytes()).map_err(log_error)?;
+    file.sync_all().map_err(log_error)
+}
+
+/// Local branches that can be dropped after their worktrees were pruned. The
+/// base branches and the repository's current branch are always kept.
+fn branches_to_delete(
+    pruned: &[Worktree],
+    base_branches: &[String],
+    current_branch: Option<&str>,
+) -> Vec<String> {
+    pruned
+        .iter()
+        .map(|wt| wt.branch.as_str())
+        .filter(|branch| !branch.is_empty() && *branch != DETACHED_HEAD)
+        .filter(|branch| !base_branches.iter().any(|base| base == branch))
+        .filter(|branch| Some(*branch) != current_branch)
+        .map(str::to_string)
+        .collect()
+}
+
+fn delete_branches(
+    context: &RepoContext,
+    branches: &[String],
+) -> Vec<(String, Result<(), String>)> {
+    // Prune already checked merge status against the base branch, which git's
+    // own check (against HEAD or upstream) doesn't know about.
+    branches
+        .iter()
+        .map(|branch| (branch.clone(), delete_branch(context, branch, true)))
+        .collect()
+}
+
+/// Worktrees that prune must never touch: the main worktree, locked worktrees,
+/// the base branches, and detached HEADs unless explicitly included.
+fn is_protected(wt: &Worktree, base_branches: &[String], include_detached: bool) -> bool {
+    if wt.is_main || wt.is_locked {
+        return true;
+    }
+    if wt.branch == DETACHED_HEAD {
+        return !include_detached;
+    }
+    base_branches.contains(&wt.branch)
+}
+
+fn select_age_candidates(
+    worktrees: &[Worktree],
+    threshold_ms: u64,
+    include_detached: bool,
+    now: DateTime<Utc>,
+) -> Vec<Worktree> {
+    let cutoff = now - chrono::Duration::milliseconds(threshold_ms as i64);
+    worktrees
+        .iter()
+        .filter(|wt| !is_protected(wt, &[], include_detached))
+        .filter(|wt| wt.created_at.timestamp() != 0 && wt.created_at <= cutoff)
+        .cloned()
+        .collect()
+}
+
+/// Worktrees old enough to prune, and those held back because their branch
+/// has unpushed commits. Age-based pruning never checks merge status, so only
+/// `--force` lets unpushed commits go; `--force-dirty` doesn't.
+fn age_candidates(
+    repo: &RepoContext,
+    worktrees: &[Worktree],
+    threshold_ms: u64,
+    options: &PruneOptions,
+    now: DateTime<Utc>,
+) -> (Vec<Worktree>, Vec<(Worktree, usize)>) {
+    let aged = select_age_candidates(worktrees, threshold_ms, options.include_detached, now);
+    skip_unpushed_candidates(repo, aged, options.force)
+}
+
+/// Split out candidates whose branch is ahead of its upstream, with how many
+/// commits would be lost. `force` keeps everything. Branches without an
+/// upstream aren't checked.
+fn skip_unpushed_candidates(
+    repo: &RepoContext,
+    candidates: Vec<Worktree>,
+    force: bool,
+) -> (Vec<Worktree>, Vec<(Worktree, usize)>) {
+    if force {
+        return (candidates, Vec::new());
+    }
+    let mut kept = Vec::new();
+    let mut skipped = Vec::new();
+    for wt in candidates {
+        let unpushed = if wt.branch == DETACHED_HEAD {
+            None
+        } else {
+            unpushed_commits(repo, &wt.branch)
+        };
+        match unpushed {
+            Some(count) if count > 0 => skipped.push((wt, count)),
+            _ => kept.push(wt),
+        }
+    }
+    (kept, skipped)
+}
+
+/// Keep candidates whose branch tip was committed at least `threshold_ms` ago.
+/// Worktrees whose tip can't be read are kept out rather than guessed at.
+fn select_inactive_candidates(
+    repo: &RepoContext,
+    candidates: Vec<Worktree>,
+    threshold_ms: u64,
+    now: DateTime<Utc>,
+) -> Vec<Worktree> {
+    let cutoff = now - chrono::Duration::milliseconds(threshold_ms as i64);
+    candidates
+        .into_iter()
+        .filter(|wt| matches!(commit_time(repo, &wt.head), Ok(time) if time <= cutoff))
+        .collect()
+}
+
+/// Split out candidates created less than `threshold_ms` before `now`, which
+/// may still be in use. Worktrees with an unknown creation time might be just
+/// as new, so they are split out too.
+fn skip_young_candidates(
+    candidates: Vec<Worktree>,
+    threshold_ms: u64,
+    now: DateTime<Utc>,
+) -> (Vec<Worktree>, Vec<Worktree>) {
+    let cutoff = now - chrono::Duration::milliseconds(threshold_ms as i64);
+    candidates
+        .into_iter()
+        .partition(|wt| wt.created_at.timestamp() != 0 && wt.created_at <= cutoff)
+}
+
+fn progress_label(wt: &Worktree) -> String {
+    if wt.branch == DETACHED_HEAD {
+        wt.path.clone()
+    } else {
+        wt.branch.clone()
+    }
 }
 
 fn get_worktree_status(wt: &Worktree) -> String {
@@ -221,3 +615,423 @@ fn get_worktree_status(wt: &Worktree) -> String {
         statuses.join(", ")
     }
 }
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{branch_exists, create_test_repo, project_root, repo_path, run_test_git};
+
+    const DAY_MS: u64 = 24 * 60 * 60 * 1000;
+
+    fn main_base() -> Vec<String> {
+        vec!["main".to_string()]
+    }
+
+    #[test]
+    fn branch_merged_into_any_base_is_a_candidate() {
+        let repo = create_test_repo("prune-multi-base");
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["branch", "release-a", "main"]);
+        run_test_git(&bare, &["branch", "release-b", "main"]);
+        let merged = repo.add_worktree("feature-merged");
+        let open = repo.add_worktree("feature-open");
+        for (worktree, file) in [(&merged, "merged.txt"), (&open, "open.txt")] {
+            std::fs::write(worktree.join(file), "work\n").unwrap();
+            run_test_git(worktree, &["add", file]);
+            run_test_git(worktree, &["commit", "-q", "-m", file]);
+        }
+        run_test_git(&bare, &["branch", "-f", "release-b", "feature-merged"]);
+        let release_a = repo.add_worktree("release-a");
+
+        let bases = vec!["release-a".to_string(), "release-b".to_string()];
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let selected = select_merged_candidates(&repo.context, &worktrees, &bases, false);
+        let branches: Vec<&str> = selected.iter().map(|wt| wt.branch.as_str()).collect();
+        assert_eq!(branches, vec!["feature-merged"]);
+
+        let base = worktrees
+            .iter()
+            .find(|wt| Path::new(&wt.path) == release_a)
+            .unwrap();
+        assert!(is_protected(base, &bases, false));
+        assert_eq!(
+            prune_reason(&bases, None, None),
+            "merged into release-a or release-b"
+        );
+    }
+
+    #[test]
+    fn size_reports_each_candidate_and_the_total() {
+        let repo = create_test_repo("prune-size");
+        let small = repo.add_worktree("feature-small");
+        let large = repo.add_worktree("feature-large");
+        std::fs::write(small.join("data.bin"), vec![0u8; 10_000]).unwrap();
+        std::fs::write(large.join("data.bin"), vec![0u8; 2_000_000]).unwrap();
+
+        let candidates: Vec<Worktree> = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .filter(|wt| wt.branch.starts_with("feature-"))
+            .collect();
+        let sizes = candidate_sizes(&candidates);
+        let size_of = |branch: &str| {
+            let index = candidates
+                .iter()
+                .position(|wt| wt.branch == branch)
+                .unwrap();
+            sizes[index]
+        };
+        assert!(size_of("feature-small") >= 10_000);
+        assert!(size_of("feature-large") >= 2_000_000);
+
+        let total: u64 = sizes.iter().sum();
+        assert_eq!(reclaimable_total(&sizes), Some(format_size(total)));
+        assert_eq!(reclaimable_total(&[]), None);
+    }
+
+    #[test]
+    fn detached_worktree_is_only_age_pruned_with_include_detached() {
+        let repo = create_test_repo("prune-detached");
+        let detached_path = project_root(&repo.context).join("scratch");
+        run_test_git(
+            repo_path(&repo.context),
+            &[
+                "worktree",
+                "add",
+                "--detach",
+                &detached_path.to_string_lossy(),
+                "main",
+            ],
+        );
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert!(worktrees.iter().any(|wt| wt.branch == DETACHED_HEAD));
+
+        let later = Utc::now() + chrono::Duration::days(2);
+        let skipped = select_age_candidates(&worktrees, DAY_MS, false, later);
+        assert!(skipped.iter().all(|wt| wt.branch != DETACHED_HEAD));
+
+        let included = select_age_candidates(&worktrees, DAY_MS, true, later);
+        assert!(included.iter().any(|wt| wt.branch == DETACHED_HEAD));
+
+        let too_young = select_age_candidates(&worktrees, DAY_MS, true, Utc::now());
+        assert!(too_young.is_empty());
+    }
+
+    #[test]
+    fn age_prune_skips_unpushed_worktrees_unless_forced() {
+        let repo = create_test_repo("prune-unpushed");
+        let unpushed = repo.add_worktree("feature-unpushed");
+        run_test_git(
+            &unpushed,
+            &["push", "-q", "-u", "origin", "feature-unpushed"],
+        );
+        run_test_git(&unpushed, &["commit", "-q", "--allow-empty", "-m", "local"]);
+        let pushed = repo.add_worktree("feature-pushed");
+        run_test_git(&pushed, &["push", "-q", "-u", "origin", "feature-pushed"]);
+
+        let later = Utc::now() + chrono::Duration::days(2);
+        let aged = || {
+            let worktrees = list_worktrees(&repo.context).unwrap();
+            select_age_candidates(&worktrees, DAY_MS, false, later)
+                .into_iter()
+                .filter(|wt| wt.branch != "main")
+                .collect::<Vec<_>>()
+        };
+
+        let (kept, skipped) = skip_unpushed_candidates(&repo.context, aged(), false);
+        let kept: Vec<&str> = kept.iter().map(|wt| wt.branch.as_str()).collect();
+        assert_eq!(kept, vec!["feature-pushed"]);
+        assert_eq!(skipped.len(), 1);
+        assert_eq!(skipped[0].0.branch, "feature-unpushed");
+        assert_eq!(skipped[0].1, 1);
+
+        let (kept, skipped) = skip_unpushed_candidates(&repo.context, aged(), true);
+        assert!(skipped.is_empty());
+        let (removed, failed) = remove_worktrees(&repo.context, &kept, true, |_| {});
+        assert_eq!(removed.len(), 2);
+        assert!(failed.is_empty());
+        assert!(!unpushed.exists());
+    }
+
+    #[test]
+    fn report_only_exit_code_fails_while_merged_worktrees_remain() {
+        let repo = create_test_repo("prune-report-only");
+        let merged = repo.add_worktree("feature-merged");
+        std::fs::write(merged.join("work.txt"), "work\n").unwrap();
+        run_test_git(&merged, &["add", "work.txt"]);
+        run_test_git(&merged, &["commit", "-q", "-m", "work"]);
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let selected = select_merged_candidates(&repo.context, &worktrees, &main_base(), true);
+        assert_eq!(report_exit_code(&selected), 0);
+
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["branch", "-f", "main", "feature-merged"]);
+        let selected = select_merged_candidates(&repo.context, &worktrees, &main_base(), true);
+        assert_eq!(selected.len(), 1);
+        assert_eq!(report_exit_code(&selected), 1);
+    }
+
+    #[test]
+    fn min_age_skips_merged_worktrees_that_were_just_created() {
+        let repo = create_test_repo("prune-min-age");
+        let merged = repo.add_worktree("feature-merged");
+        std::fs::write(merged.join("work.txt"), "work\n").unwrap();
+        run_test_git(&merged, &["add", "work.txt"]);
+        run_test_git(&merged, &["commit", "-q", "-m", "work"]);
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["branch", "-f", "main", "feature-merged"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let selected = select_merged_candidates(&repo.context, &worktrees, &main_base(), false);
+        assert_eq!(selected.len(), 1);
+
+        let hour_ms = parse_duration("1h").unwrap();
+        let (kept, young) = skip_young_candidates(selected.clone(), hour_ms, Utc::now());
+        assert!(kept.is_empty());
+        assert_eq!(young[0].branch, "feature-merged");
+
+        // The same worktree an hour and a bit later is old enough to prune.
+        let later = Utc::now() + chrono::Duration::minutes(61);
+        let (kept, young) = skip_young_candidates(selected.clone(), hour_ms, later);
+        assert_eq!(kept.len(), 1);
+        assert!(young.is_empty());
+
+        // An unreadable creation time is never taken as old enough.
+        let mut unknown = selected[0].clone();
+        unknown.created_at = DateTime::from_timestamp(0, 0).unwrap();
+        let (kept, young) = skip_young_candidates(vec![unknown], hour_ms, later);
+        assert!(kept.is_empty());
+        assert_eq!(young[0].branch, "feature-merged");
+    }
+
+    fn prune_options(force: bool, force_dirty: bool) -> PruneOptions {
+        PruneOptions {
+            dry_run: false,
+            report_only: false,
+            force,
+            force_dirty,
+            base_branches: Vec::new(),
+            older_than: Some("1d".to_string()),
+            since_last_commit: None,
+            min_age: None,
+            include_detached: false,
+            remove_branch: false,
+            parallel: None,
+            log: None,
+            size: false,
+            absolute_created: false,
+        }
+    }
+
+    #[test]
+    fn force_dirty_keeps_the_unpushed_guard_and_neither_flag_prunes_locked() {
+        let repo = create_test_repo("prune-force-dirty");
+        let locked = repo.add_worktree("feature-locked");
+        std::fs::write(locked.join("wip.txt"), "wip\n").unwrap();
+        run_test_git(&locked, &["worktree", "lock", "--reason", "in use", "."]);
+        let unpushed = repo.add_worktree("feature-unpushed");
+        run_test_git(
+            &unpushed,
+            &["push", "-q", "-u", "origin", "feature-unpushed"],
+        );
+        run_test_git(&unpushed, &["commit", "-q", "--allow-empty", "-m", "local"]);
+        std::fs::write(unpushed.join("wip.txt"), "wip\n").unwrap();
+
+        let worktrees: Vec<Worktree> = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .filter(|wt| wt.branch != "main")
+            .collect();
+        assert!(worktrees.iter().all(|wt| wt.is_dirty));
+        assert!(worktrees
+            .iter()
+            .any(|wt| wt.branch == "feature-locked" && wt.is_locked));
+        let later = Utc::now() + chrono::Duration::days(2);
+        let branches = |options: &PruneOptions| {
+            let (kept, skipped) = age_candidates(&repo.context, &worktrees, DAY_MS, options, later);
+            let names =
+                |wts: Vec<&Worktree>| wts.iter().map(|wt| wt.branch.clone()).collect::<Vec<_>>();
+            (
+                names(kept.iter().collect()),
+                names(skipped.iter().map(|(wt, _)| wt).collect()),
+            )
+        };
+
+        // Locked worktrees are never candidates, whichever guard is lifted.
+        let (kept, skipped) = branches(&prune_options(false, true));
+        assert!(kept.is_empty());
+        assert_eq!(skipped, vec!["feature-unpushed"]);
+        assert!(!skipped.contains(&"feature-locked".to_string()));
+
+        let (kept, skipped) = branches(&prune_options(true, false));
+        assert_eq!(kept, vec!["feature-unpushed"]);
+        assert!(skipped.is_empty());
+        assert!(!kept.contains(&"feature-locked".to_string()));
+    }
+
+    fn commit_with_date(worktree: &std::path::Path, date: &str) {
+        let output = std::process::Command::new("git")
+            .args([
+                "-c",
+                "user.name=Grove Test",
+                "-c",
+                "user.email=test@grove.dev",
+            ])
+            .args(["commit", "-q", "--allow-empty", "-m", "work"])
+            .env("GIT_COMMITTER_DATE", date)
+            .current_dir(worktree)
+            .output()
+            .unwrap();
+        assert!(output.status.success());
+    }
+
+    #[test]
+    fn since_last_commit_uses_branch_tip_committer_date() {
+        let repo = create_test_repo("prune-since-last-commit");
+        let stale = repo.add_worktree("feature-stale");
+        let active = repo.add_worktree("feature-active");
+        let now = Utc::now();
+        commit_with_date(&stale, &(now - chrono::Duration::days(40)).to_rfc3339());
+        commit_with_date(&active, &(now - chrono::Duration::days(20)).to_rfc3339());
+
+        let candidates: Vec<Worktree> = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .filter(|wt| !is_protected(wt, &main_base(), false))
+            .collect();
+        let selected = select_inactive_candidates(&repo.context, candidates, 30 * DAY_MS, now);
+
+        let branches: Vec<&str> = selected.iter().map(|wt| wt.branch.as_str()).collect();
+        assert_eq!(branches, vec!["feature-stale"]);
+    }
+
+    #[test]
+    fn removal_reports_progress_for_each_worktree() {
+        let repo = create_test_repo("prune-progress");
+        repo.add_worktree("feature-x");
+        repo.add_worktree("feature-y");
+
+        let candidates: Vec<Worktree> = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .filter(|wt| !wt.is_main && wt.branch != "main")
+            .collect();
+        assert_eq!(candidates.len(), 2);
+
+        let mut progress = Progress::new(Vec::new(), candidates.len(), true);
+        let (removed, failed) = remove_worktrees(&repo.context, &candidates, true, |wt| {
+            progress.step("removing", &progress_label(wt));
+        });
+        assert_eq!(removed.len(), 2);
+        assert!(failed.is_empty());
+
+        let output = String::from_utf8(progress.into_inner()).unwrap();
+        let lines: Vec<&str> = output.lines().collect();
+        assert_eq!(lines.len(), 2);
+        assert!(lines[0].starts_with("[1/2] removing feature-"));
+        assert!(lines[1].starts_with("[2/2] removing feature-"));
+    }
+
+    #[test]
+    fn prune_log_appends_a_line_per_removed_worktree() {
+        let repo = create_test_repo("prune-log");
+        repo.add_worktree("feature-x");
+        repo.add_worktree("feature-y");
+
+        let candidates: Vec<Worktree> = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .filter(|wt| !is_protected(wt, &main_base(), false))
+            .collect();
+        let (removed, failed) = remove_worktrees(&repo.context, &candidates, true, |_| {});
+        assert_eq!(removed.len(), 2);
+        assert!(failed.is_empty());
+
+        let log_path = project_root(&repo.context).join("logs").join("prune.jsonl");
+        std::fs::create_dir_all(log_path.parent().unwrap()).unwrap();
+        std::fs::write(&log_path, "{\"earlier\":true}\n").unwrap();
+        let pruned: Vec<&Worktree> = candidates
+            .iter()
+            .filter(|wt| removed.contains(&wt.path))
+            .collect();
+        let reason = prune_reason(&main_base(), None, None);
+        append_prune_log(&log_path, &pruned, &reason, Utc::now()).unwrap();
+
+        let content = std::fs::read_to_string(&log_path).unwrap();
+        let lines: Vec<serde_json::Value> = content
+            .lines()
+            .map(|line| serde_json::from_str(line).unwrap())
+            .collect();
+        assert_eq!(lines.len(), 3);
+        assert_eq!(lines[0]["earlier"], true);
+        for (line, wt) in lines[1..].iter().zip(&candidates) {
+            assert_eq!(line["path"], wt.path.as_str());
+            assert_eq!(line["branch"], wt.branch.as_str());
+            assert_eq!(line["head"], wt.head.as_str());
+            assert_eq!(line["reason"], "merged into main");
+            assert!(line["timestamp"].as_str().unwrap().ends_with('Z'));
+        }
+    }
+
+    #[test]
+    fn remove_branch_deletes_only_pruned_branches() {
+        let repo = create_test_repo("prune-remove-branch");
+        let merged = repo.add_worktree("feature-merged");
+        let open = repo.add_worktree("feature-open");
+        for (worktree, file) in [(&merged, "merged.txt"), (&open, "open.txt")] {
+            std::fs::write(worktree.join(file), "work\n").unwrap();
+            run_test_git(worktree, &["add", file]);
+            run_test_git(worktree, &["commit", "-q", "-m", file]);
+        }
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["branch", "-f", "main", "feature-merged"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let selected = select_merged_candidates(&repo.context, &worktrees, &main_base(), false);
+        let (removed, failed) = remove_worktrees(&repo.context, &selected, true, |_| {});
+        assert_eq!(removed.len(), 1);
+        assert!(failed.is_empty());
+
+        let branches = branches_to_delete(&selected, &main_base(), Some("main"));
+        assert_eq!(branches, vec!["feature-merged".to_string()]);
+        for (_, result) in delete_branches(&repo.context, &branches) {
+            result.unwrap();
+        }
+
+        assert!(!branch_exists(&repo.context, "feature-merged"));
+        assert!(!merged.exists());
+        assert!(branch_exists(&repo.context, "feature-open"));
+        assert!(open.exists());
+    }
+
+    #[test]
+    fn branches_to_delete_skips_base_current_and_detached() {
+        let mut base = make_pruned("main");
+        base.is_main = true;
+        let pruned = vec![
+            base,
+            make_pruned("trunk"),
+            make_pruned(DETACHED_HEAD),
+            make_pruned("feature"),
+        ];
+
+        let branches = branches_to_delete(&pruned, &main_base(), Some("trunk"));
+        assert_eq!(branches, vec!["feature".to_string()]);
+    }
+
+    fn make_pruned(branch: &str) -> Worktree {
+        Worktree {
+            path: format!("/repo/{}", branch),
+            branch: branch.to_string(),
+            head: "abc123".to_string(),
+            created_at: DateTime::from_timestamp(0, 0).unwrap(),
+            is_dirty: false,
+            is_locked: false,
+            lock_reason: None,
+            is_prunable: false,
+            is_main: false,
+            is_dangling: false,
+        }
+    }
+}
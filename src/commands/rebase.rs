@@ -0,0 +1,226 @@
+use colored::Colorize;
+
+use crate::git::{
+    discover_repo, get_default_branch, get_worktree, list_worktrees, rebase_worktree,
+    RebaseOutcome, RepoContext, DETACHED_HEAD,
+};
+use crate::models::Worktree;
+use crate::progress::Progress;
+use crate::utils::trim_trailing_branch_slashes;
+
+pub fn run(name: Option<&str>, all: bool, onto: Option<&str>) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let onto = match onto.map(trim_trailing_branch_slashes) {
+        Some(base) if !base.is_empty() => base.to_string(),
+        _ => match get_default_branch(&repo) {
+            Ok(b) => b,
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        },
+    };
+
+    let (targets, skipped) = if all {
+        let worktrees = match list_worktrees(&repo) {
+            Ok(wts) => wts,
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        };
+        select_rebase_targets(worktrees, &onto)
+    } else {
+        let name = trim_trailing_branch_slashes(name.unwrap_or_default());
+        let worktree = match get_worktree(&repo, name) {
+            Ok(wt) => wt,
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        };
+        if let Some(reason) = skip_reason(&worktree, &onto) {
+            eprintln!(
+                "{} Cannot rebase {}: {}",
+                "Error:".red(),
+                worktree.branch,
+                reason
+            );
+            std::process::exit(1);
+        }
+        (vec![worktree], Vec::new())
+    };
+
+    for (wt, reason) in &skipped {
+        println!(
+            "{}",
+            format!("- {}: skipped ({})", wt.branch, reason).dimmed()
+        );
+    }
+
+    if targets.is_empty() {
+        println!("{}", "No worktrees to rebase.".yellow());
+        return;
+    }
+
+    let mut progress = Progress::stderr(targets.len(), false);
+    let results = rebase_worktrees(&repo, &targets, &onto, |wt| {
+        progress.step("rebasing", &wt.branch);
+    });
+
+    let mut needs_attention = 0;
+    for (wt, result) in &results {
+        match result {
+            Ok(RebaseOutcome::Rebased) => {
+                println!(
+                    "{}",
+                    format!("✓ {}: rebased onto {}", wt.branch, onto).green()
+                );
+            }
+            Ok(RebaseOutcome::UpToDate) => {
+                println!(
+                    "{}",
+                    format!("= {}: already up to date", wt.branch).dimmed()
+                );
+            }
+            Ok(RebaseOutcome::Conflict) => {
+                needs_attention += 1;
+                println!(
+                    "{}",
+                    format!(
+                        "✗ {}: conflicts. Resolve them in {} and run 'git rebase --continue' (or 'git rebase --abort').",
+                        wt.branch, wt.path
+                    )
+                    .red()
+                );
+            }
+            Err(e) => {
+                needs_attention += 1;
+                println!("{}", format!("✗ {}: {}", wt.branch, e).red());
+            }
+        }
+    }
+
+    if needs_attention > 0 {
+        std::process::exit(1);
+    }
+}
+
+/// Why a worktree can't be rebased onto `onto`, if it can't.
+fn skip_reason(wt: &Worktree, onto: &str) -> Option<&'static str> {
+    if wt.is_main || wt.branch == onto {
+        Some("base branch")
+    } else if wt.branch == DETACHED_HEAD {
+        Some("detached HEAD")
+    } else if wt.is_prunable {
+        Some("worktree directory is missing")
+    } else if wt.is_locked {
+        Some("locked")
+    } else if wt.is_dirty {
+        Some("uncommitted changes")
+    } else {
+        None
+    }
+}
+
+fn select_rebase_targets(
+    worktrees: Vec<Worktree>,
+    onto: &str,
+) -> (Vec<Worktree>, Vec<(Worktree, &'static str)>) {
+    let mut targets = Vec::new();
+    let mut skipped = Vec::new();
+    for wt in worktrees {
+        match skip_reason(&wt, onto) {
+            Some(reason) => skipped.push((wt, reason)),
+            None => targets.push(wt),
+        }
+    }
+    (targets, skipped)
+}
+
+/// Rebase each worktree independently; a conflict in one does not stop the rest.
+fn rebase_worktrees<F: FnMut(&Worktree)>(
+    repo: &RepoContext,
+    targets: &[Worktree],
+    onto: &str,
+    mut on_start: F,
+) -> Vec<(Worktree, Result<RebaseOutcome, String>)> {
+    targets
+        .iter()
+        .map(|wt| {
+            on_start(wt);
+            (wt.clone(), rebase_worktree(repo, &wt.path, onto))
+        })
+        .collect()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, run_test_git};
+    use std::fs;
+    use std::path::Path;
+
+    fn commit_file(worktree: &Path, file: &str, content: &str) {
+        fs::write(worktree.join(file), content).unwrap();
+        run_test_git(worktree, &["add", file]);
+        run_test_git(worktree, &["commit", "-q", "-m", file]);
+    }
+
+    #[test]
+    fn rebase_all_reports_rebased_up_to_date_and_conflict() {
+        let repo = create_test_repo("rebase-all");
+        let clean = repo.add_worktree("feature-clean");
+        let conflicting = repo.add_worktree("feature-conflict");
+        let dirty = repo.add_worktree("feature-dirty");
+        commit_file(&clean, "clean.txt", "clean");
+        commit_file(&conflicting, "README.md", "feature version");
+
+        let main = repo.add_worktree("main");
+        commit_file(&main, "README.md", "main version");
+        repo.add_worktree("feature-fresh");
+        fs::write(dirty.join("wip.txt"), "wip").unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let (targets, skipped) = select_rebase_targets(worktrees, "main");
+        let mut skipped: Vec<(String, &str)> = skipped
+            .into_iter()
+            .map(|(wt, reason)| (wt.branch, reason))
+            .collect();
+        skipped.sort();
+        assert_eq!(
+            skipped,
+            vec![
+                ("feature-dirty".to_string(), "uncommitted changes"),
+                ("main".to_string(), "base branch"),
+            ]
+        );
+
+        let mut outcomes: Vec<(String, RebaseOutcome)> =
+            rebase_worktrees(&repo.context, &targets, "main", |_| {})
+                .into_iter()
+                .map(|(wt, result)| (wt.branch, result.unwrap()))
+                .collect();
+        outcomes.sort_by(|a, b| a.0.cmp(&b.0));
+        assert_eq!(
+            outcomes,
+            vec![
+                ("feature-clean".to_string(), RebaseOutcome::Rebased),
+                ("feature-conflict".to_string(), RebaseOutcome::Conflict),
+                ("feature-fresh".to_string(), RebaseOutcome::UpToDate),
+            ]
+        );
+
+        // The conflicted rebase is left in progress for manual resolution.
+        run_test_git(&conflicting, &["rev-parse", "--verify", "REBASE_HEAD"]);
+        let merged_base = run_test_git(&clean, &["merge-base", "--is-ancestor", "main", "HEAD"]);
+        assert!(merged_base.is_empty());
+    }
+}
@@ -0,0 +1,416 @@
+use colored::Colorize;
+use std::fs;
+use std::path::{Component, Path, PathBuf};
+
+use crate::git::{discover_repo, list_worktrees, move_worktree, project_root, RepoContext};
+use crate::models::Worktree;
+use crate::utils::{branch_glob_matches, load_repo_config, DEFAULT_IDE_FILES};
+
+struct PlannedMove {
+    from: PathBuf,
+    to: PathBuf,
+}
+
+struct RelocationPlan {
+    root: PathBuf,
+    moves: Vec<PlannedMove>,
+    skipped: Vec<(String, String)>,
+}
+
+pub fn run(directory: &str, force: bool, update_ide: bool) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktrees = match list_worktrees(&repo) {
+        Ok(wts) => wts,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    // Read the patterns up front so a broken .groverc fails before anything moves.
+    let ide_patterns = if update_ide {
+        match load_repo_config(project_root(&repo)) {
+            Ok(config) => config
+                .ide_files
+                .unwrap_or_else(|| DEFAULT_IDE_FILES.iter().map(|p| p.to_string()).collect()),
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+    } else {
+        Vec::new()
+    };
+
+    let plan = match plan_relocation(&worktrees, project_root(&repo), directory, force) {
+        Ok(plan) => plan,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    for (path, reason) in &plan.skipped {
+        println!("{}", format!("Skipping {}: {}", path, reason).yellow());
+    }
+
+    if plan.moves.is_empty() {
+        println!("{}", "No worktrees to relocate.".yellow());
+        return;
+    }
+
+    if let Err(e) = apply_moves(&repo, &plan) {
+        eprintln!("{} {}", "Error:".red(), e);
+        std::process::exit(1);
+    }
+
+    for planned in &plan.moves {
+        println!(
+            "{}",
+            format!(
+                "✓ Moved {} → {}",
+                planned.from.display(),
+                planned.to.display()
+            )
+            .green()
+        );
+    }
+    if update_ide {
+        let (updated, warnings) = update_ide_files(&plan.moves, &ide_patterns);
+        for warning in &warnings {
+            eprintln!("{} {}", "Warning:".yellow(), warning);
+        }
+        for file in &updated {
+            println!("{}", format!("Updated {}", file.display()).dimmed());
+        }
+    }
+    println!(
+        "{}",
+        format!(
+            "\nRelocated {} worktree(s) into {}.",
+            plan.moves.len(),
+            directory
+        )
+        .green()
+    );
+}
+
+/// Decide where every worktree under the project root should go, failing before
+/// anything is moved if any destination is unusable.
+fn plan_relocation(
+    worktrees: &[Worktree],
+    project_root: &Path,
+    directory: &str,
+    force: bool,
+) -> Result<RelocationPlan, String> {
+    let relative_dir = Path::new(directory);
+    let is_plain_relative = relative_dir
+        .components()
+        .all(|c| matches!(c, Component::Normal(_)));
+    if directory.trim().is_empty() || !is_plain_relative {
+        return Err(format!(
+            "Invalid directory '{}': must be a relative path inside the project root",
+            directory
+        ));
+    }
+
+    let root = fs::canonicalize(project_root).unwrap_or_else(|_| project_root.to_path_buf());
+    let target = root.join(relative_dir);
+
+    let mut moves = Vec::new();
+    let mut skipped = Vec::new();
+
+    for wt in worktrees {
+        let path = fs::canonicalize(&wt.path).unwrap_or_else(|_| PathBuf::from(&wt.path));
+        let relative = match path.strip_prefix(&root) {
+            Ok(rel) if !rel.as_os_str().is_empty() => rel.to_path_buf(),
+            _ => {
+                skipped.push((wt.path.clone(), "outside the project root".to_string()));
+                continue;
+            }
+        };
+
+        if path.starts_with(&target) {
+            skipped.push((wt.path.clone(), format!("already under {}", directory)));
+            continue;
+        }
+        if target.starts_with(&path) {
+            return Err(format!(
+                "Cannot relocate into '{}': it is inside worktree {}",
+                directory, wt.path
+            ));
+        }
+        if wt.is_prunable {
+            skipped.push((wt.path.clone(), "worktree directory is missing".to_string()));
+            continue;
+        }
+        if wt.is_locked {
+            skipped.push((wt.path.clone(), "worktree is locked".to_string()));
+            continue;
+        }
+        if wt.is_dirty && !force {
+            skipped.push((
+                wt.path.clone(),
+                "has uncommitted changes (use --force to move it anyway)".to_string(),
+            ));
+            continue;
+        }
+
+        let destination = target.join(&relative);
+        if destination.exists() {
+            return Err(format!(
+                "Destination '{}' already exists; no worktrees were moved",
+                destination.display()
+            ));
+        }
+
+        moves.push(PlannedMove {
+            from: path,
+            to: destination,
+        });
+    }
+
+    Ok(RelocationPlan {
+        root,
+        moves,
+        skipped,
+    })
+}
+
+/// Perform the planned moves in order. If one fails, the moves already made are
+/// undone in reverse so the layout is left as it was found.
+fn apply_moves(context: &RepoContext, plan: &RelocationPlan) -> Result<(), String> {
+    let moves = &plan.moves;
+    for (index, planned) in moves.iter().enumerate() {
+        let result = planned
+            .to
+            .parent()
+            .map_or(Ok(()), fs::create_dir_all)
+            .map_err(|e| format!("Failed to create {}: {}", planned.to.display(), e))
+            .and_then(|_| move_worktree(context, &planned.from, &planned.to));
+
+        if let Err(e) = result {
+            let mut rollback_errors = Vec::new();
+            for done in moves[..index].iter().rev() {
+                if let Err(rollback_error) = move_worktree(context, &done.to, &done.from) {
+                    rollback_errors.push(rollback_error);
+                }
+            }
+            if rollback_errors.is_empty() {
+                return Err(format!("{}; rolled back {} move(s)", e, index));
+            }
+            return Err(format!(
+                "{}; rollback failed: {}",
+                e,
+                rollback_errors.join("; ")
+            ));
+        }
+    }
+
+    for planned in moves {
+        remove_empty_parents(&planned.from, &plan.root);
+    }
+
+    Ok(())
+}
+
+/// Point each moved worktree's editor files at the new locations. This is
+/// best-effort: files that can't be read or written come back as warnings
+/// alongside the files that were updated.
+fn update_ide_files(moves: &[PlannedMove], patterns: &[String]) -> (Vec<PathBuf>, Vec<String>) {
+    let mut updated = Vec::new();
+    let mut warnings = Vec::new();
+    for planned in moves {
+        for file in ide_files(&planned.to, patterns) {
+            let content = match fs::read_to_string(&file) {
+                Ok(content) => content,
+                Err(e) => {
+                    warnings.push(format!("Could not read {}: {}", file.display(), e));
+                    continue;
+                }
+            };
+            let rewritten = moves.iter().fold(content.clone(), |text, m| {
+                replace_path(&text, &m.from, &m.to)
+            });
+            if rewritten == content {
+                continue;
+            }
+            match fs::write(&file, rewritten) {
+                Ok(()) => updated.push(file),
+                Err(e) => warnings.push(format!("Could not update {}: {}", file.display(), e)),
+            }
+        }
+    }
+    (updated, warnings)
+}
+
+/// Files in `root` matching `patterns`. Only the last component of a pattern
+/// may hold wildcards, so finding them never walks the whole worktree.
+fn ide_files(root: &Path, patterns: &[String]) -> Vec<PathBuf> {
+    let mut files = Vec::new();
+    for pattern in patterns {
+        let (dir, name) = match pattern.rsplit_once('/') {
+            Some((dir, name)) => (root.join(dir), name),
+            None => (root.to_path_buf(), pattern.as_str()),
+        };
+        let Ok(entries) = fs::read_dir(&dir) else {
+            continue;
+        };
+        for entry in entries.flatten() {
+            let path = entry.path();
+            if path.is_file()
+                && branch_glob_matches(name, &entry.file_name().to_string_lossy())
+                && !files.contains(&path)
+            {
+                files.push(path);
+            }
+        }
+    }
+    files
+}
+
+/// Replace `from` with `to` in `text`, skipping matches that continue into a
+/// longer name, so `/p/feature` doesn't rewrite `/p/feature-2`. Paths below
+/// `from` move with it.
+fn replace_path(text: &str, from: &Path, to: &Path) -> String {
+    let from = from.to_string_lossy();
+    let to = to.to_string_lossy();
+    let mut result = String::with_capacity(text.len());
+    let mut rest = text;
+    while let Some(index) = rest.find(from.as_ref()) {
+        let end = index + from.len();
+        let continues_name = rest[end..]
+            .chars()
+            .next()
+            .is_some_and(|c| c.is_alphanumeric() || "-_.".contains(c));
+        result.push_str(&rest[..index]);
+        result.push_str(if continues_name { &from } else { &to });
+        rest = &rest[end..];
+    }
+    result.push_str(rest);
+    result
+}
+
+/// Nested worktrees (e.g. `feature/foo`) leave empty parent directories behind.
+fn remove_empty_parents(moved_from: &Path, root: &Path) {
+    let mut current = moved_from.parent();
+    while let Some(dir) = current {
+        if dir == root || fs::remove_dir(dir).is_err() {
+            break;
+        }
+        current = dir.parent();
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, run_test_git};
+
+    #[test]
+    fn relocates_worktrees_into_subdirectory() {
+        let repo = create_test_repo("relocate-root");
+        repo.add_worktree("feature-a");
+        repo.add_worktree("feature/b");
+        let root = fs::canonicalize(project_root(&repo.context)).unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let plan = plan_relocation(&worktrees, &root, "worktrees", false).unwrap();
+        assert_eq!(plan.moves.len(), 2);
+        apply_moves(&repo.context, &plan).unwrap();
+
+        let relocated = list_worktrees(&repo.context).unwrap();
+        assert_eq!(relocated.len(), 2);
+        for wt in &relocated {
+            let path = Path::new(&wt.path);
+            assert!(path.starts_with(root.join("worktrees")));
+            let branch = run_test_git(path, &["rev-parse", "--abbrev-ref", "HEAD"]);
+            assert_eq!(branch.trim(), wt.branch);
+        }
+        assert!(root.join("worktrees").join("feature").join("b").is_dir());
+        assert!(!root.join("feature").exists());
+    }
+
+    #[test]
+    fn update_ide_rewrites_workspace_paths_after_move() {
+        let repo = create_test_repo("relocate-root-ide");
+        repo.add_worktree("feature-a");
+        let root = fs::canonicalize(project_root(&repo.context)).unwrap();
+        let old = root.join("feature-a");
+        let workspace = format!(
+            "{{\"folders\": [{{\"path\": \"{0}\"}}, {{\"path\": \"{0}/docs\"}}, {{\"path\": \"{0}-notes\"}}]}}\n",
+            old.display()
+        );
+        fs::create_dir_all(old.join(".vscode")).unwrap();
+        fs::write(old.join(".vscode/project.code-workspace"), &workspace).unwrap();
+        fs::create_dir_all(old.join(".idea")).unwrap();
+        fs::write(old.join(".idea/broken.iml"), [0xff, 0xfe]).unwrap();
+
+        // The fixture files are untracked, so the move needs --force.
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let plan = plan_relocation(&worktrees, &root, "worktrees", true).unwrap();
+        apply_moves(&repo.context, &plan).unwrap();
+        let patterns: Vec<String> = DEFAULT_IDE_FILES.iter().map(|p| p.to_string()).collect();
+        let (updated, warnings) = update_ide_files(&plan.moves, &patterns);
+
+        let new = root.join("worktrees").join("feature-a");
+        let file = new.join(".vscode/project.code-workspace");
+        assert_eq!(updated, vec![file.clone()]);
+        assert_eq!(
+            fs::read_to_string(&file).unwrap(),
+            format!(
+                "{{\"folders\": [{{\"path\": \"{}\"}}, {{\"path\": \"{}/docs\"}}, {{\"path\": \"{}-notes\"}}]}}\n",
+                new.display(),
+                new.display(),
+                old.display()
+            )
+        );
+        assert_eq!(warnings.len(), 1);
+        assert!(warnings[0].contains("broken.iml"));
+    }
+
+    #[test]
+    fn existing_destination_aborts_before_moving() {
+        let repo = create_test_repo("relocate-root-conflict");
+        let first = repo.add_worktree("feature-a");
+        repo.add_worktree("feature-b");
+        let root = fs::canonicalize(project_root(&repo.context)).unwrap();
+        fs::create_dir_all(root.join("worktrees").join("feature-b")).unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let err = plan_relocation(&worktrees, &root, "worktrees", false)
+            .err()
+            .unwrap();
+        assert!(err.contains("already exists"));
+        assert!(first.join(".git").exists());
+    }
+
+    #[test]
+    fn dirty_worktrees_are_skipped_without_force() {
+        let repo = create_test_repo("relocate-root-dirty");
+        let dirty = repo.add_worktree("feature-dirty");
+        fs::write(dirty.join("scratch.txt"), "wip").unwrap();
+        let root = fs::canonicalize(project_root(&repo.context)).unwrap();
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let plan = plan_relocation(&worktrees, &root, "worktrees", false).unwrap();
+        assert!(plan.moves.is_empty());
+        assert_eq!(plan.skipped.len(), 1);
+
+        let forced = plan_relocation(&worktrees, &root, "worktrees", true).unwrap();
+        assert_eq!(forced.moves.len(), 1);
+    }
+
+    #[test]
+    fn rejects_directory_outside_project_root() {
+        assert!(plan_relocation(&[], Path::new("/tmp"), "../elsewhere", false).is_err());
+        assert!(plan_relocation(&[], Path::new("/tmp"), "/abs", false).is_err());
+    }
+}
@@ -2,11 +2,14 @@ use std::collections::HashSet;
 
 use colored::Colorize;
 
-use crate::git::{discover_repo, list_worktrees, remove_worktree};
+use crate::git::{
+    delete_branch, discover_repo, list_worktrees, remove_worktree, resolve_worktree_by, MatchBy,
+    RepoContext, DETACHED_HEAD,
+};
 use crate::models::Worktree;
-use crate::utils::trim_trailing_branch_slashes;
+use crate::prompt::choose_worktree;
 
-pub fn run(names: &[String], force: bool, yes: bool) {
+pub fn run(names: &[String], force: bool, yes: bool, delete_branches: bool, by: MatchBy) {
     let repo = match discover_repo() {
         Ok(m) => m,
         Err(e) => {
@@ -26,7 +29,7 @@ pub fn run(names: &[String], force: bool, yes: bool) {
     let targets = if names.is_empty() {
         vec![pick_worktree_to_remove(&worktrees)]
     } else {
-        match resolve_worktrees_to_remove(&worktrees, names) {
+        match resolve_worktrees_to_remove(&worktrees, names, by) {
             Ok(targets) => targets,
             Err(e) => {
                 eprintln!("{} {}", "Error:".red(), e);
@@ -72,6 +75,21 @@ pub fn run(names: &[String], force: bool, yes: bool) {
                         worktree.branch
                     );
                 }
+
+                if delete_branches {
+                    match delete_worktree_branch(&repo, worktree, force) {
+                        Ok(true) => println!(
+                            "{}",
+                            format!("✓ Deleted branch: {}", worktree.branch).green()
+                        ),
+                        Ok(false) => {}
+                        Err(e) => eprintln!(
+                            "{} {} Use --force to delete it anyway.",
+                            "Warning:".yellow(),
+                            e
+                        ),
+                    }
+                }
             }
             Err(e) => failed.push((worktree.branch.clone(), e)),
         }
@@ -90,26 +108,24 @@ pub fn run(names: &[String], force: bool, yes: bool) {
     }
 }
 
-fn find_worktree_by_identifier<'a>(
-    worktrees: &'a [Worktree],
-    identifier: &str,
-) -> Option<&'a Worktree> {
-    let trimmed_identifier = identifier.trim();
-    let normalized_branch = trim_trailing_branch_slashes(trimmed_identifier);
-    let normalized_path = trimmed_identifier.trim_end_matches('/');
-
-    worktrees.iter().find(|wt| {
-        wt.path == trimmed_identifier
-            || wt.path.trim_end_matches('/') == normalized_path
-            || (!normalized_branch.is_empty()
-                && (wt.branch == normalized_branch
-                    || wt.path.ends_with(&format!("/{}", normalized_branch))))
-    })
+/// Delete the branch a removed worktree had checked out. Returns `Ok(false)`
+/// when there is no branch to delete. Without `force`, git keeps branches
+/// that aren't fully merged.
+fn delete_worktree_branch(
+    repo: &RepoContext,
+    worktree: &Worktree,
+    force: bool,
+) -> Result<bool, String> {
+    if worktree.branch.is_empty() || worktree.branch == DETACHED_HEAD || worktree.is_dangling {
+        return Ok(false);
+    }
+    delete_branch(repo, &worktree.branch, force).map(|()| true)
 }
 
 fn resolve_worktrees_to_remove(
     worktrees: &[Worktree],
     identifiers: &[String],
+    by: MatchBy,
 ) -> Result<Vec<Worktree>, String> {
     let mut resolved = Vec::new();
     let mut seen_paths = HashSet::new();
@@ -120,13 +136,9 @@ fn resolve_worktrees_to_remove(
             continue;
         }
 
-        let worktree =
-            find_worktree_by_identifier(worktrees, trimmed_identifier).ok_or_else(|| {
-                format!(
-                    "Worktree '{}' not found. Use 'grove list' to see available worktrees.",
-                    trimmed_identifier
-                )
-            })?;
+        // Removal deletes work, so a partial name never picks a worktree.
+        let worktree = resolve_worktree_by(worktrees, trimmed_identifier, by.exact())
+            .map_err(|e| e.to_string())?;
 
         if seen_paths.insert(worktree.path.clone()) {
             resolved.push(worktree.clone());
@@ -197,40 +209,33 @@ fn pick_worktree_to_remove(worktrees: &[Worktree]) -> Worktree {
         std::process::exit(1);
     }
 
-    if !atty::is(atty::Stream::Stdin) {
-        eprintln!("{}", "Error: Interactive selection requires a TTY.".red());
-        eprintln!(
-            "{}",
-            "Provide a branch name argument or use 'grove list' instead.".dimmed()
-        );
-        std::process::exit(1);
-    }
-
-    let items: Vec<String> = removable
-        .iter()
-        .map(|wt| format!("{} ({})", wt.branch, wt.path))
-        .collect();
-
-    let selection: Result<Option<usize>, _> = dialoguer::FuzzySelect::new()
-        .with_prompt("Select a worktree to remove (type to search)")
-        .items(&items)
-        .interact_opt();
-
-    match selection {
-        Ok(Some(idx)) => removable[idx].clone(),
-        _ => {
+    match choose_worktree(&removable, "Select a worktree to remove") {
+        Ok(Some(wt)) => wt.clone(),
+        Ok(None) => {
             println!("{}", "Selection cancelled.".dimmed());
             std::process::exit(0);
         }
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            eprintln!(
+                "{}",
+                "Provide a branch name argument or use 'grove list' instead.".dimmed()
+            );
+            std::process::exit(1);
+        }
     }
 }
 
 #[cfg(test)]
 mod tests {
     use super::{
-        find_worktree_by_identifier, removal_confirmation_message, resolve_worktrees_to_remove,
+        delete_worktree_branch, removal_confirmation_message, resolve_worktrees_to_remove,
         validate_worktrees_for_removal,
     };
+    use crate::git::{
+        branch_exists, create_test_repo, list_worktrees, remove_worktree, resolve_worktree,
+        run_test_git, MatchBy, DETACHED_HEAD,
+    };
     use crate::models::Worktree;
     use chrono::DateTime;
 
@@ -242,37 +247,33 @@ mod tests {
             created_at: DateTime::from_timestamp(0, 0).unwrap(),
             is_dirty: false,
             is_locked: false,
+            lock_reason: None,
             is_prunable: false,
             is_main: false,
+            is_dangling: false,
         }
     }
 
     #[test]
-    fn find_worktree_by_identifier_matches_branch_with_trailing_slash() {
+    fn resolve_worktree_matches_branch_with_trailing_slash() {
         let worktrees = vec![make_worktree(
             "/repo/feature/my-branch",
             "feature/my-branch",
         )];
 
-        let found = find_worktree_by_identifier(&worktrees, "feature/my-branch/");
-        assert_eq!(
-            found.map(|wt| wt.branch.as_str()),
-            Some("feature/my-branch")
-        );
+        let found = resolve_worktree(&worktrees, "feature/my-branch/");
+        assert_eq!(found.map(|wt| wt.branch.as_str()), Ok("feature/my-branch"));
     }
 
     #[test]
-    fn find_worktree_by_identifier_matches_path_with_trailing_slash() {
+    fn resolve_worktree_matches_path_with_trailing_slash() {
         let worktrees = vec![make_worktree(
             "/repo/feature/my-branch",
             "feature/my-branch",
         )];
 
-        let found = find_worktree_by_identifier(&worktrees, "/repo/feature/my-branch/");
-        assert_eq!(
-            found.map(|wt| wt.branch.as_str()),
-            Some("feature/my-branch")
-        );
+        let found = resolve_worktree(&worktrees, "/repo/feature/my-branch/");
+        assert_eq!(found.map(|wt| wt.branch.as_str()), Ok("feature/my-branch"));
     }
 
     #[test]
@@ -289,6 +290,7 @@ mod tests {
                 "/repo/feature/one".to_string(),
                 "feature/two".to_string(),
             ],
+            MatchBy::Any,
         )
         .unwrap();
 
@@ -301,11 +303,41 @@ mod tests {
         let worktrees = vec![make_worktree("/repo/feature/one", "feature/one")];
 
         let err =
-            resolve_worktrees_to_remove(&worktrees, &["feature/two".to_string()]).unwrap_err();
+            resolve_worktrees_to_remove(&worktrees, &["feature/two".to_string()], MatchBy::Any)
+                .unwrap_err();
 
         assert!(err.contains("feature/two"));
     }
 
+    #[test]
+    fn resolve_worktrees_to_remove_ignores_partial_names() {
+        let worktrees = vec![make_worktree("/repo/feature-a", "feature-a")];
+
+        let err = resolve_worktrees_to_remove(&worktrees, &["feat".to_string()], MatchBy::Any)
+            .unwrap_err();
+        assert!(err.contains("'feat' not found"), "{}", err);
+
+        let resolved =
+            resolve_worktrees_to_remove(&worktrees, &["feature-a".to_string()], MatchBy::Any)
+                .unwrap();
+        assert_eq!(resolved[0].path, "/repo/feature-a");
+    }
+
+    #[test]
+    fn resolve_worktrees_to_remove_by_branch_skips_matching_directory() {
+        let worktrees = vec![
+            make_worktree("/repo/review", "feature-x"),
+            make_worktree("/repo/wip", "review"),
+        ];
+        let names = ["review".to_string()];
+
+        let resolved = resolve_worktrees_to_remove(&worktrees, &names, MatchBy::Branch).unwrap();
+        assert_eq!(resolved[0].path, "/repo/wip");
+
+        let resolved = resolve_worktrees_to_remove(&worktrees, &names, MatchBy::Path).unwrap();
+        assert_eq!(resolved[0].path, "/repo/review");
+    }
+
     #[test]
     fn validate_worktrees_for_removal_blocks_dirty_without_force() {
         let mut dirty = make_worktree("/repo/feature/dirty", "feature/dirty");
@@ -338,4 +370,44 @@ mod tests {
             "Are you sure you want to remove these 2 worktrees: feature/one, feature/two?"
         );
     }
+
+    #[test]
+    fn delete_branch_after_removal_guards_unmerged_branches() {
+        let repo = create_test_repo("remove-delete-branch");
+        repo.add_worktree("feature-merged");
+        let unmerged = repo.add_worktree("feature-unmerged");
+        run_test_git(&unmerged, &["commit", "-q", "--allow-empty", "-m", "wip"]);
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let find = |branch: &str| worktrees.iter().find(|wt| wt.branch == branch).unwrap();
+        for branch in ["feature-merged", "feature-unmerged"] {
+            remove_worktree(&repo.context, &find(branch).path, false).unwrap();
+        }
+
+        assert!(delete_worktree_branch(&repo.context, find("feature-merged"), false).unwrap());
+        assert!(!branch_exists(&repo.context, "feature-merged"));
+
+        let err =
+            delete_worktree_branch(&repo.context, find("feature-unmerged"), false).unwrap_err();
+        assert!(err.contains("feature-unmerged"));
+        assert!(branch_exists(&repo.context, "feature-unmerged"));
+        assert!(delete_worktree_branch(&repo.context, find("feature-unmerged"), true).unwrap());
+        assert!(!branch_exists(&repo.context, "feature-unmerged"));
+    }
+
+    #[test]
+    fn keep_branch_leaves_branch_and_detached_has_nothing_to_delete() {
+        let repo = create_test_repo("remove-keep-branch");
+        repo.add_worktree("feature-kept");
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let kept = worktrees
+            .iter()
+            .find(|wt| wt.branch == "feature-kept")
+            .unwrap();
+        remove_worktree(&repo.context, &kept.path, false).unwrap();
+        assert!(branch_exists(&repo.context, "feature-kept"));
+
+        let detached = make_worktree("/repo/detached", DETACHED_HEAD);
+        assert!(!delete_worktree_branch(&repo.context, &detached, true).unwrap());
+    }
 }
@@ -0,0 +1,238 @@
+use colored::Colorize;
+
+use crate::git::{
+    commits_ahead, dirty_file_counts, discover_repo, get_worktree_by, reset_worktree,
+    resolve_revision_in, upstream_branch, DirtyFileCounts, MatchBy, RepoContext,
+    WorktreeLookupError, DETACHED_HEAD,
+};
+use crate::models::Worktree;
+use crate::utils::trim_trailing_branch_slashes;
+
+/// Where a reset goes: the ref as the user gave it (or the upstream), and the
+/// commit it named in the worktree when grove checked it.
+#[derive(Debug, Clone, PartialEq, Eq)]
+struct ResetTarget {
+    name: String,
+    commit: String,
+}
+
+/// What a hard reset throws away: commits on the branch that the target
+/// doesn't have, and uncommitted changes to tracked files.
+#[derive(Debug, Default, Clone, Copy, PartialEq, Eq)]
+struct Discarded {
+    commits: usize,
+    files: DirtyFileCounts,
+}
+
+pub fn run(name: &str, to: Option<&str>, force: bool, allow_main: bool) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let worktree = match worktree_to_reset(&repo, name) {
+        Ok(wt) => wt,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    if worktree.is_main && !allow_main {
+        eprintln!(
+            "{} {} is the main worktree. Pass --allow-main to reset it anyway.",
+            "Error:".red(),
+            worktree.branch
+        );
+        std::process::exit(1);
+    }
+
+    let target = match reset_target(&repo, &worktree, to) {
+        Ok(target) => target,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    let discarded = match discarded_changes(&repo, &worktree, &target) {
+        Ok(discarded) => discarded,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    if !force {
+        eprintln!(
+            "{} Resetting {} to {} would discard {}. Pass --force to reset.",
+            "Error:".red(),
+            worktree.branch,
+            target.name,
+            describe_discarded(&discarded)
+        );
+        std::process::exit(1);
+    }
+
+    // Reset to the commit the report was computed against, so the two agree.
+    if let Err(e) = reset_worktree(&repo, &worktree.path, &target.commit) {
+        eprintln!("{} {}", "Error:".red(), e);
+        std::process::exit(1);
+    }
+
+    println!(
+        "{} {} to {}",
+        "✓ Reset".green(),
+        worktree.branch.bold(),
+        target.name
+    );
+    println!(
+        "{}",
+        format!("Discarded {}", describe_discarded(&discarded)).dimmed()
+    );
+    if discarded.files.untracked > 0 {
+        println!(
+            "{}",
+            format!(
+                "{} untracked file(s) were left in place",
+                discarded.files.untracked
+            )
+            .dimmed()
+        );
+    }
+}
+
+/// The worktree `name` names exactly: a hard reset throws work away, so a
+/// partial name never picks one.
+fn worktree_to_reset(repo: &RepoContext, name: &str) -> Result<Worktree, WorktreeLookupError> {
+    get_worktree_by(repo, trim_trailing_branch_slashes(name), MatchBy::Exact)
+}
+
+/// The ref to reset to: `to` when given, else the branch's upstream. Resolved
+/// inside the worktree, not the bare clone, so relative refs like `HEAD~1`
+/// and `@{u}` mean what they would in that checkout.
+fn reset_target(
+    repo: &RepoContext,
+    worktree: &Worktree,
+    to: Option<&str>,
+) -> Result<ResetTarget, String> {
+    let name = match to {
+        Some(to) => to.to_string(),
+        None if worktree.branch == DETACHED_HEAD => {
+            return Err(format!(
+                "{} has a detached HEAD. Pass --to <ref> to choose what to reset to.",
+                worktree.path
+            ));
+        }
+        None => upstream_branch(repo, &worktree.branch).ok_or_else(|| {
+            format!(
+                "Branch '{}' has no upstream. Pass --to <ref> to choose what to reset to.",
+                worktree.branch
+            )
+        })?,
+    };
+    let commit = resolve_revision_in(repo, &worktree.path, &name)?;
+    Ok(ResetTarget { name, commit })
+}
+
+fn discarded_changes(
+    repo: &RepoContext,
+    worktree: &Worktree,
+    target: &ResetTarget,
+) -> Result<Discarded, String> {
+    Ok(Discarded {
+        commits: commits_ahead(repo, &target.commit, &worktree.head)?,
+        files: dirty_file_counts(&worktree.path, false)?,
+    })
+}
+
+fn describe_discarded(discarded: &Discarded) -> String {
+    let mut parts = Vec::new();
+    if discarded.commits > 0 {
+        parts.push(format!("{} commit(s)", discarded.commits));
+    }
+    if discarded.files.staged > 0 {
+        parts.push(format!("{} staged change(s)", discarded.files.staged));
+    }
+    if discarded.files.unstaged > 0 {
+        parts.push(format!("{} unstaged change(s)", discarded.files.unstaged));
+    }
+    if parts.is_empty() {
+        "nothing".to_string()
+    } else {
+        parts.join(", ")
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, run_test_git};
+    use std::fs;
+
+    #[test]
+    fn reset_discards_commits_and_changes_down_to_the_target() {
+        let repo = create_test_repo("reset-dirty");
+        let worktree_path = repo.add_worktree("feature-x");
+        fs::write(worktree_path.join("feature.txt"), "feature").unwrap();
+        run_test_git(&worktree_path, &["add", "feature.txt"]);
+        run_test_git(&worktree_path, &["commit", "-q", "-m", "feature"]);
+        fs::write(worktree_path.join("README.md"), "# Changed\n").unwrap();
+        fs::write(worktree_path.join("staged.txt"), "staged").unwrap();
+        run_test_git(&worktree_path, &["add", "staged.txt"]);
+
+        let worktree = worktree_to_reset(&repo.context, "feature-x").unwrap();
+        assert!(reset_target(&repo.context, &worktree, None).is_err());
+        let target = reset_target(&repo.context, &worktree, Some("main")).unwrap();
+        let discarded = discarded_changes(&repo.context, &worktree, &target).unwrap();
+        assert_eq!(
+            describe_discarded(&discarded),
+            "1 commit(s), 1 staged change(s), 1 unstaged change(s)"
+        );
+
+        reset_worktree(&repo.context, &worktree.path, &target.commit).unwrap();
+        assert_eq!(
+            run_test_git(&worktree_path, &["rev-parse", "HEAD"]),
+            run_test_git(&worktree_path, &["rev-parse", "main"])
+        );
+        assert!(run_test_git(&worktree_path, &["status", "--porcelain"]).is_empty());
+        assert!(!worktree_path.join("feature.txt").exists());
+    }
+
+    #[test]
+    fn relative_refs_resolve_in_the_worktree() {
+        let repo = create_test_repo("reset-relative");
+        let worktree_path = repo.add_worktree("feature-x");
+        for file in ["one.txt", "two.txt"] {
+            fs::write(worktree_path.join(file), file).unwrap();
+            run_test_git(&worktree_path, &["add", file]);
+            run_test_git(&worktree_path, &["commit", "-q", "-m", file]);
+        }
+        let parent = run_test_git(&worktree_path, &["rev-parse", "HEAD~1"]);
+
+        let worktree = worktree_to_reset(&repo.context, "feature-x").unwrap();
+        let target = reset_target(&repo.context, &worktree, Some("HEAD~1")).unwrap();
+        assert_eq!(target.name, "HEAD~1");
+        assert_eq!(target.commit, parent.trim());
+        let discarded = discarded_changes(&repo.context, &worktree, &target).unwrap();
+        assert_eq!(describe_discarded(&discarded), "1 commit(s)");
+
+        reset_worktree(&repo.context, &worktree.path, &target.commit).unwrap();
+        assert_eq!(run_test_git(&worktree_path, &["rev-parse", "HEAD"]), parent);
+        assert!(worktree_path.join("one.txt").exists());
+        assert!(!worktree_path.join("two.txt").exists());
+    }
+
+    #[test]
+    fn partial_names_do_not_pick_a_worktree() {
+        let repo = create_test_repo("reset-exact");
+        repo.add_worktree("bugfix-login");
+
+        assert!(matches!(
+            worktree_to_reset(&repo.context, "fix"),
+            Err(WorktreeLookupError::NotFound(_))
+        ));
+        assert!(worktree_to_reset(&repo.context, "bugfix-login/").is_ok());
+    }
+}
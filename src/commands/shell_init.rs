@@ -1,7 +1,7 @@
 use colored::Colorize;
 use std::env;
 
-use crate::utils::{read_config, write_config};
+use crate::utils::{read_config, set_config_key};
 
 const BASH_ZSH_FUNCTION: &str = r#"grove() {
   local grove_bin=""
@@ -111,11 +111,10 @@ pub fn should_show_shell_tip() -> bool {
     config.shell_tip_shown != Some(true)
 }
 
-/// Mark the shell tip as shown so it won't appear again.
+/// Mark the shell tip as shown so it won't appear again. Failing to record
+/// it (e.g. the config file doesn't parse) only means the tip shows again.
 pub fn mark_shell_tip_shown() {
-    let mut config = read_config();
-    config.shell_tip_shown = Some(true);
-    write_config(&config);
+    let _ = set_config_key("shellTipShown", serde_json::Value::Bool(true));
 }
 
 /// Get the shell integration setup instructions for the detected shell.
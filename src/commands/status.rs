@@ -0,0 +1,246 @@
+use colored::Colorize;
+use serde::Serialize;
+use std::env;
+use std::path::PathBuf;
+
+use crate::commands::worktree_root::find_worktree_root;
+use crate::git::{
+    dirty_file_counts, discover_repo, list_worktrees, worktree_status, RepoContext, WorktreeStatus,
+};
+
+/// A worktree's status for `--json`. Optional fields are always present and
+/// null when they don't apply, so consumers don't have to check for both.
+#[derive(Debug, Serialize)]
+struct StatusReport {
+    path: String,
+    /// Null when HEAD is detached.
+    branch: Option<String>,
+    head: String,
+    upstream: Option<String>,
+    ahead: usize,
+    behind: usize,
+    staged: usize,
+    modified: usize,
+    untracked: usize,
+    dirty: bool,
+}
+
+pub fn run(short: bool, json: bool, all: bool) {
+    if all {
+        let repo = match discover_repo() {
+            Ok(m) => m,
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        };
+        match all_reports(&repo) {
+            Ok(reports) => print_json(&reports),
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    let cwd = env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    let Some(root) = find_worktree_root(&cwd) else {
+        eprintln!("{} Not inside a grove worktree.", "Error:".red());
+        std::process::exit(1);
+    };
+
+    let status = match worktree_status(&root.to_string_lossy()) {
+        Ok(status) => status,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    if short {
+        println!("{}", format_short(&status));
+        return;
+    }
+
+    if json {
+        match status_report(&root.to_string_lossy(), status) {
+            Ok(report) => print_json(&report),
+            Err(e) => {
+                eprintln!("{} {}", "Error:".red(), e);
+                std::process::exit(1);
+            }
+        }
+        return;
+    }
+
+    let branch = match &status.branch {
+        Some(branch) => branch.bold().to_string(),
+        None => format!("detached at {}", short_hash(&status.head)),
+    };
+    println!("{} {}", "Branch:  ".dimmed(), branch);
+    let upstream = match &status.upstream {
+        Some(upstream) => format!("{} ({})", upstream, divergence(&status)),
+        None => "none".to_string(),
+    };
+    println!("{} {}", "Upstream:".dimmed(), upstream);
+    let changes = if status.dirty {
+        "uncommitted changes".yellow()
+    } else {
+        "clean".green()
+    };
+    println!("{} {}", "Changes: ".dimmed(), changes);
+}
+
+fn print_json<T: Serialize>(value: &T) {
+    match serde_json::to_string_pretty(value) {
+        Ok(output) => println!("{}", output),
+        Err(e) => {
+            eprintln!("{} Failed to serialize JSON: {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    }
+}
+
+fn status_report(path: &str, status: WorktreeStatus) -> Result<StatusReport, String> {
+    let counts = dirty_file_counts(path, false)?;
+    Ok(StatusReport {
+        path: path.to_string(),
+        branch: status.branch,
+        head: status.head,
+        upstream: status.upstream,
+        ahead: status.ahead,
+        behind: status.behind,
+        staged: counts.staged,
+        modified: counts.unstaged,
+        untracked: counts.untracked,
+        dirty: status.dirty,
+    })
+}
+
+/// Status of every worktree whose directory still exists, in `git worktree list` order.
+fn all_reports(repo: &RepoContext) -> Result<Vec<StatusReport>, String> {
+    list_worktrees(repo)?
+        .iter()
+        .filter(|wt| !wt.is_prunable)
+        .map(|wt| status_report(&wt.path, worktree_status(&wt.path)?))
+        .collect()
+}
+
+/// One line for prompts and scripts: `<name>[ <markers>]`. The name is the
+/// branch, or `@` and the abbreviated commit when HEAD is detached. Markers
+/// always come in this order and are left out when they don't apply:
+/// `*` for uncommitted changes, `+N` for commits ahead of the upstream, and
+/// `-N` for commits behind it. e.g. `feature-x *+2-1`, `main -3`, `@1a2b3c4`.
+fn format_short(status: &WorktreeStatus) -> String {
+    let name = match &status.branch {
+        Some(branch) => branch.clone(),
+        None => format!("@{}", short_hash(&status.head)),
+    };
+
+    let mut markers = String::new();
+    if status.dirty {
+        markers.push('*');
+    }
+    if status.ahead > 0 {
+        markers.push_str(&format!("+{}", status.ahead));
+    }
+    if status.behind > 0 {
+        markers.push_str(&format!("-{}", status.behind));
+    }
+
+    if markers.is_empty() {
+        name
+    } else {
+        format!("{} {}", name, markers)
+    }
+}
+
+fn divergence(status: &WorktreeStatus) -> String {
+    match (status.ahead, status.behind) {
+        (0, 0) => "up to date".to_string(),
+        (ahead, 0) => format!("{} ahead", ahead),
+        (0, behind) => format!("{} behind", behind),
+        (ahead, behind) => format!("{} ahead, {} behind", ahead, behind),
+    }
+}
+
+fn short_hash(head: &str) -> &str {
+    &head[..head.len().min(7)]
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, run_test_git};
+    use std::fs;
+
+    #[test]
+    fn short_status_for_dirty_diverged_worktree() {
+        let repo = create_test_repo("status-short");
+        let main = repo.add_worktree("main");
+        let feature = repo.add_worktree("feature-x");
+        run_test_git(&feature, &["branch", "--set-upstream-to=main"]);
+        for message in ["one", "two"] {
+            run_test_git(&feature, &["commit", "-q", "--allow-empty", "-m", message]);
+        }
+        run_test_git(&main, &["commit", "-q", "--allow-empty", "-m", "three"]);
+        fs::write(feature.join("notes.txt"), "wip\n").unwrap();
+
+        let status = worktree_status(&feature.to_string_lossy()).unwrap();
+        assert_eq!(format_short(&status), "feature-x *+2-1");
+
+        let status = worktree_status(&main.to_string_lossy()).unwrap();
+        assert_eq!(format_short(&status), "main");
+    }
+
+    #[test]
+    fn json_report_for_one_worktree_and_all() {
+        let repo = create_test_repo("status-json");
+        let main = repo.add_worktree("main");
+        let feature = repo.add_worktree("feature-x");
+        run_test_git(&feature, &["branch", "--set-upstream-to=main"]);
+        run_test_git(&feature, &["commit", "-q", "--allow-empty", "-m", "one"]);
+        fs::write(feature.join("README.md"), "# Changed\n").unwrap();
+        fs::write(feature.join("staged.txt"), "staged\n").unwrap();
+        run_test_git(&feature, &["add", "staged.txt"]);
+        fs::write(feature.join("notes.txt"), "wip\n").unwrap();
+
+        let path = feature.to_string_lossy();
+        let report = status_report(&path, worktree_status(&path).unwrap()).unwrap();
+        let value: serde_json::Value =
+            serde_json::from_str(&serde_json::to_string_pretty(&report).unwrap()).unwrap();
+        assert_eq!(value["path"], path.as_ref());
+        assert_eq!(value["branch"], "feature-x");
+        assert_eq!(value["upstream"], "main");
+        assert_eq!(value["ahead"], 1);
+        assert_eq!(value["behind"], 0);
+        assert_eq!(value["staged"], 1);
+        assert_eq!(value["modified"], 1);
+        assert_eq!(value["untracked"], 1);
+        assert_eq!(value["dirty"], true);
+
+        let reports = all_reports(&repo.context).unwrap();
+        let value: serde_json::Value =
+            serde_json::from_str(&serde_json::to_string_pretty(&reports).unwrap()).unwrap();
+        let entries = value.as_array().unwrap();
+        assert_eq!(entries.len(), 2);
+        let main_entry = entries
+            .iter()
+            .find(|entry| entry["path"] == main.to_string_lossy().as_ref())
+            .unwrap();
+        assert_eq!(main_entry["branch"], "main");
+        // A missing upstream is null, not left out.
+        assert!(main_entry["upstream"].is_null());
+        assert_eq!(main_entry["dirty"], false);
+    }
+
+    #[test]
+    fn short_status_for_detached_head() {
+        let status = WorktreeStatus {
+            head: "1a2b3c4d5e6f".to_string(),
+            ..Default::default()
+        };
+        assert_eq!(format_short(&status), "@1a2b3c4");
+    }
+}
@@ -1,7 +1,22 @@
 use colored::Colorize;
+use std::env;
+use std::fs;
+use std::path::{Component, Path, PathBuf};
+use std::sync::Mutex;
 
-use crate::git::{discover_repo, get_default_branch, list_worktrees, sync_branch};
-use crate::utils::trim_trailing_branch_slashes;
+use crate::commands::add::{resolve_template_dir, set_up_worktree, WorktreeSetup};
+use crate::git::{
+    add_worktree, branch_exists, discover_repo, find_remote_branch, get_default_branch,
+    list_worktrees, project_root, remote_url, sync_branch, RepoContext,
+};
+use crate::inventory::{parse_inventory, Inventory, InventoryEntry};
+use crate::utils::{load_repo_config, parallel_map, trim_trailing_branch_slashes};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum RestoreOutcome {
+    Created,
+    AlreadyPresent,
+}
 
 pub fn run(branch: Option<&str>) {
     let repo = match discover_repo() {
@@ -64,3 +79,258 @@ pub fn run(branch: Option<&str>) {
         "from origin".dimmed()
     );
 }
+
+/// Recreate the worktrees listed in an inventory written by `grove export`,
+/// up to `jobs` at a time.
+pub fn restore(file: &str, jobs: usize) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let inventory = match fs::read_to_string(file)
+        .map_err(|e| format!("Failed to read {}: {}", file, e))
+        .and_then(|content| {
+            parse_inventory(&content).map_err(|e| format!("Invalid inventory {}: {}", file, e))
+        }) {
+        Ok(inventory) => inventory,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    if let (Some(expected), Some(actual)) = (inventory.url.as_deref(), remote_url(&repo)) {
+        if expected != actual {
+            println!(
+                "{}",
+                format!(
+                    "Warning: inventory was exported from {}, but origin is {}",
+                    expected, actual
+                )
+                .yellow()
+            );
+        }
+    }
+    if let (Some(expected), Ok(actual)) = (inventory.base.as_deref(), get_default_branch(&repo)) {
+        if expected != actual {
+            println!(
+                "{}",
+                format!(
+                    "Warning: inventory default branch is {}, but this clone uses {}",
+                    expected, actual
+                )
+                .yellow()
+            );
+        }
+    }
+
+    let repo_config = match load_repo_config(project_root(&repo)) {
+        Ok(config) => config,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    let commands = repo_config
+        .bootstrap
+        .as_ref()
+        .map(|bootstrap| bootstrap.commands.as_slice())
+        .unwrap_or_default();
+    let cwd = env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    let template = match resolve_template_dir(
+        None,
+        repo_config.worktree_template_dir.as_deref(),
+        project_root(&repo),
+        &cwd,
+    ) {
+        Ok(template) => template,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+    // Local files come from the default branch's worktree, when there is one.
+    let copy_source = get_default_branch(&repo).ok().and_then(|base| {
+        list_worktrees(&repo)
+            .ok()?
+            .into_iter()
+            .find(|wt| wt.branch == base)
+    });
+    let setup = WorktreeSetup {
+        copy_files: &repo_config.copy_files,
+        copy_source: copy_source.as_ref(),
+        template: template.as_deref(),
+        commands,
+    };
+
+    let mut failures = 0;
+    for (entry, result) in restore_inventory(&repo, &inventory, &setup, jobs) {
+        match result {
+            Ok(RestoreOutcome::Created) => println!(
+                "{}",
+                format!("✓ {}: created at {}", entry.branch, entry.directory).green()
+            ),
+            Ok(RestoreOutcome::AlreadyPresent) => println!(
+                "{}",
+                format!("= {}: {} already exists", entry.branch, entry.directory).dimmed()
+            ),
+            Err(e) => {
+                failures += 1;
+                println!("{}", format!("✗ {}: {}", entry.branch, e).red());
+            }
+        }
+    }
+
+    if failures > 0 {
+        std::process::exit(1);
+    }
+}
+
+/// Create a worktree for every inventory entry whose directory doesn't exist
+/// yet, then set it up the way `grove add` would. Each entry is handled
+/// independently so one failure doesn't stop the rest, and up to `jobs`
+/// entries are handled at once.
+pub fn restore_inventory(
+    repo: &RepoContext,
+    inventory: &Inventory,
+    setup: &WorktreeSetup,
+    jobs: usize,
+) -> Vec<(InventoryEntry, Result<RestoreOutcome, String>)> {
+    // Git updates shared metadata (config, worktrees/, refs) when adding a
+    // worktree, so only the bootstrap commands actually run in parallel.
+    let git_lock = Mutex::new(());
+    let results = parallel_map(&inventory.worktrees, jobs, |entry| {
+        let outcome = {
+            let _guard = git_lock.lock().unwrap();
+            restore_entry(repo, entry)?
+        };
+        if outcome == RestoreOutcome::Created {
+            let path = project_root(repo).join(&entry.directory);
+            set_up_worktree(&path, &entry.branch, setup)?;
+        }
+        Ok(outcome)
+    });
+    inventory.worktrees.iter().cloned().zip(results).collect()
+}
+
+fn restore_entry(repo: &RepoContext, entry: &InventoryEntry) -> Result<RestoreOutcome, String> {
+    let relative = Path::new(&entry.directory);
+    if !relative
+        .components()
+        .all(|c| matches!(c, Component::Normal(_)))
+    {
+        return Err(format!(
+            "Invalid directory '{}': must be a relative path inside the project root",
+            entry.directory
+        ));
+    }
+
+    let path = project_root(repo).join(relative);
+    if path.exists() {
+        return Ok(RestoreOutcome::AlreadyPresent);
+    }
+    let path = path.to_string_lossy();
+
+    if branch_exists(repo, &entry.branch) {
+        add_worktree(repo, &path, &entry.branch, false, None)?;
+    } else if let Some(remote) = find_remote_branch(repo, &entry.branch, true) {
+        add_worktree(repo, &path, &entry.branch, true, Some(&remote))?;
+    } else {
+        return Err(format!(
+            "Branch '{}' was not found locally or on origin",
+            entry.branch
+        ));
+    }
+    Ok(RestoreOutcome::Created)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, repo_path, run_test_git};
+    use crate::utils::BootstrapCommand;
+
+    #[test]
+    fn parallel_restore_creates_every_worktree_and_runs_hooks() {
+        let repo = create_test_repo("sync-parallel-restore");
+        let branches = [
+            "feature-a",
+            "feature-b",
+            "feature-c",
+            "feature-d",
+            "nested/e",
+        ];
+        for branch in branches {
+            run_test_git(repo_path(&repo.context), &["branch", branch, "main"]);
+        }
+        let inventory = Inventory {
+            url: None,
+            base: None,
+            worktrees: branches
+                .iter()
+                .map(|branch| InventoryEntry {
+                    branch: branch.to_string(),
+                    directory: branch.to_string(),
+                    created_at: None,
+                })
+                .collect(),
+        };
+        let commands = [BootstrapCommand {
+            program: "sh".to_string(),
+            args: vec!["-c".to_string(), "touch bootstrapped".to_string()],
+        }];
+
+        let setup = WorktreeSetup {
+            commands: &commands,
+            ..WorktreeSetup::default()
+        };
+        let results = restore_inventory(&repo.context, &inventory, &setup, 4);
+        for (entry, result) in &results {
+            assert_eq!(result, &Ok(RestoreOutcome::Created), "{}", entry.branch);
+            let path = project_root(&repo.context).join(&entry.directory);
+            assert!(path.join("bootstrapped").exists(), "{}", entry.branch);
+        }
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert_eq!(worktrees.len(), branches.len());
+    }
+
+    #[test]
+    fn restore_copies_local_files_like_add() {
+        let repo = create_test_repo("sync-restore-copies");
+        let main = repo.add_worktree("main");
+        fs::write(main.join(".env"), "SECRET=1\n").unwrap();
+        run_test_git(repo_path(&repo.context), &["branch", "feature-a", "main"]);
+        let source = list_worktrees(&repo.context)
+            .unwrap()
+            .into_iter()
+            .find(|wt| wt.branch == "main")
+            .unwrap();
+        let inventory = Inventory {
+            url: None,
+            base: None,
+            worktrees: vec![InventoryEntry {
+                branch: "feature-a".to_string(),
+                directory: "feature-a".to_string(),
+                created_at: None,
+            }],
+        };
+        let copy_files = [".env".to_string()];
+        let setup = WorktreeSetup {
+            copy_files: &copy_files,
+            copy_source: Some(&source),
+            ..WorktreeSetup::default()
+        };
+
+        let results = restore_inventory(&repo.context, &inventory, &setup, 2);
+        assert_eq!(results[0].1, Ok(RestoreOutcome::Created));
+        let restored = project_root(&repo.context).join("feature-a");
+        assert_eq!(
+            fs::read_to_string(restored.join(".env")).unwrap(),
+            "SECRET=1\n"
+        );
+    }
+}
@@ -0,0 +1,231 @@
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use colored::Colorize;
+
+use crate::git::{
+    branches_not_on_remote, discover_repo, list_worktrees, project_root, repo_path, RepoContext,
+    DETACHED_HEAD,
+};
+use crate::models::Worktree;
+
+pub fn run(force: bool, yes: bool) {
+    let repo = match discover_repo() {
+        Ok(m) => m,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    let targets = match teardown_targets(&repo, force) {
+        Ok(targets) => targets,
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    };
+
+    println!("{}", "This will permanently delete:".yellow());
+    for target in &targets {
+        println!("  {}", target.display());
+    }
+
+    if !yes
+        && !dialoguer::Confirm::new()
+            .with_prompt(format!(
+                "Delete the grove layout for {}?",
+                project_root(&repo).display()
+            ))
+            .default(false)
+            .interact()
+            .unwrap_or(false)
+    {
+        println!("{}", "Operation cancelled.".blue());
+        return;
+    }
+
+    match tear_down(&repo, &targets) {
+        Ok(true) => println!(
+            "{} {}",
+            "✓ Removed grove layout:".green(),
+            project_root(&repo).display()
+        ),
+        Ok(false) => {
+            println!(
+                "{} {}",
+                "✓ Removed grove layout:".green(),
+                project_root(&repo).display()
+            );
+            println!(
+                "{}",
+                "The project directory was kept because it still contains other files.".dimmed()
+            );
+        }
+        Err(e) => {
+            eprintln!("{} {}", "Error:".red(), e);
+            std::process::exit(1);
+        }
+    }
+}
+
+/// Everything `uninit` deletes: each worktree directory that still exists,
+/// then the bare clone. Without `force`, fails if any worktree has
+/// uncommitted changes or any local branch, checked out or not, has commits
+/// that aren't on a remote.
+fn teardown_targets(repo: &RepoContext, force: bool) -> Result<Vec<PathBuf>, String> {
+    let worktrees = list_worktrees(repo)?;
+
+    if !force {
+        let mut unsaved: Vec<String> = worktrees
+            .iter()
+            .filter(|wt| wt.is_dirty)
+            .map(|wt| format!("  {}: uncommitted changes", display_name(wt)))
+            .collect();
+        unsaved.extend(
+            branches_not_on_remote(repo)?
+                .into_iter()
+                .map(|(branch, count)| {
+                    format!(
+                        "  branch {}: {} commit{} not on any remote",
+                        branch,
+                        count,
+                        if count == 1 { "" } else { "s" }
+                    )
+                }),
+        );
+        if !unsaved.is_empty() {
+            return Err(format!(
+                "Deleting the layout would lose work:\n{}\nCommit and push it (run 'grove sync' first if it is already pushed), or pass --force to delete everything anyway.",
+                unsaved.join("\n")
+            ));
+        }
+    }
+
+    let mut targets: Vec<PathBuf> = worktrees
+        .iter()
+        .map(|wt| PathBuf::from(&wt.path))
+        .filter(|path| path.exists())
+        .collect();
+    targets.push(repo_path(repo).to_path_buf());
+    Ok(targets)
+}
+
+fn display_name(worktree: &Worktree) -> &str {
+    if worktree.branch.is_empty() || worktree.branch == DETACHED_HEAD {
+        &worktree.path
+    } else {
+        &worktree.branch
+    }
+}
+
+/// Delete `targets` in order, then the project root if nothing else is left
+/// in it. Returns whether the project root was removed.
+fn tear_down(repo: &RepoContext, targets: &[PathBuf]) -> Result<bool, String> {
+    // Worktrees go first so a failure part way through leaves the bare clone
+    // behind to inspect or repair.
+    for target in targets {
+        fs::remove_dir_all(target)
+            .map_err(|e| format!("Failed to delete {}: {}", target.display(), e))?;
+    }
+    Ok(remove_if_empty(project_root(repo)))
+}
+
+fn remove_if_empty(dir: &Path) -> bool {
+    fs::read_dir(dir).is_ok_and(|mut entries| entries.next().is_none())
+        && fs::remove_dir(dir).is_ok()
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, list_worktrees, run_test_git, TestRepo};
+
+    /// A layout whose branches are all on origin, as after `grove sync`.
+    fn synced_test_repo(name: &str) -> TestRepo {
+        let repo = create_test_repo(name);
+        run_test_git(repo_path(&repo.context), &["fetch", "-q", "origin"]);
+        repo
+    }
+
+    #[test]
+    fn refuses_dirty_worktree_and_deletes_everything_with_force() {
+        let repo = synced_test_repo("uninit-dirty");
+        let main = repo.add_worktree("main");
+        let feature = repo.add_worktree("feature-x");
+        fs::write(feature.join("README.md"), "# Changed\n").unwrap();
+
+        let error = teardown_targets(&repo.context, false).unwrap_err();
+        assert!(
+            error.contains("feature-x: uncommitted changes"),
+            "{}",
+            error
+        );
+        assert!(!error.contains("main"), "{}", error);
+        assert!(feature.exists());
+
+        let targets = teardown_targets(&repo.context, true).unwrap();
+        assert_eq!(targets.len(), 3);
+        assert_eq!(targets.last().unwrap(), repo_path(&repo.context));
+
+        assert!(tear_down(&repo.context, &targets).unwrap());
+        assert!(!main.exists());
+        assert!(!feature.exists());
+        assert!(!repo_path(&repo.context).exists());
+        assert!(!project_root(&repo.context).exists());
+    }
+
+    #[test]
+    fn refuses_unpushed_commits() {
+        let repo = synced_test_repo("uninit-unpushed");
+        let main = repo.add_worktree("main");
+        run_test_git(&main, &["branch", "--set-upstream-to", "origin/main"]);
+        fs::write(main.join("notes.txt"), "local only\n").unwrap();
+        run_test_git(&main, &["add", "notes.txt"]);
+        run_test_git(&main, &["commit", "-q", "-m", "Local"]);
+
+        let error = teardown_targets(&repo.context, false).unwrap_err();
+        assert!(
+            error.contains("branch main: 1 commit not on any remote"),
+            "{}",
+            error
+        );
+    }
+
+    #[test]
+    fn refuses_never_pushed_branch_without_a_worktree() {
+        let repo = synced_test_repo("uninit-local-branch");
+        repo.add_worktree("main");
+        let feature = repo.add_worktree("feature-local");
+        for file in ["one.txt", "two.txt"] {
+            fs::write(feature.join(file), "local only\n").unwrap();
+            run_test_git(&feature, &["add", file]);
+            run_test_git(&feature, &["commit", "-q", "-m", file]);
+        }
+        // The branch has no upstream and outlives its worktree.
+        run_test_git(
+            repo_path(&repo.context),
+            &["worktree", "remove", &feature.to_string_lossy()],
+        );
+
+        let error = teardown_targets(&repo.context, false).unwrap_err();
+        assert!(
+            error.contains("branch feature-local: 2 commits not on any remote"),
+            "{}",
+            error
+        );
+        assert!(teardown_targets(&repo.context, true).is_ok());
+    }
+
+    #[test]
+    fn keeps_project_root_with_other_files() {
+        let repo = synced_test_repo("uninit-keep-root");
+        repo.add_worktree("main");
+        fs::write(project_root(&repo.context).join("notes.md"), "keep\n").unwrap();
+
+        let targets = teardown_targets(&repo.context, false).unwrap();
+        assert!(!tear_down(&repo.context, &targets).unwrap());
+        assert!(project_root(&repo.context).join("notes.md").exists());
+        assert!(list_worktrees(&repo.context).is_err());
+    }
+}
@@ -0,0 +1,63 @@
+use colored::Colorize;
+use std::env;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use crate::utils::{extract_bare_clone_from_gitdir, parse_git_file};
+
+pub fn run() {
+    let cwd = env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    match find_worktree_root(&cwd) {
+        Some(root) => println!("{}", root.display()),
+        None => {
+            eprintln!("{} Not inside a grove worktree.", "Error:".red());
+            std::process::exit(1);
+        }
+    }
+}
+
+/// Walk up from `start` to the nearest directory whose `.git` file links it
+/// to a bare clone as a linked worktree.
+pub fn find_worktree_root(start: &Path) -> Option<PathBuf> {
+    let start = fs::canonicalize(start).unwrap_or_else(|_| start.to_path_buf());
+
+    for dir in start.ancestors() {
+        let git_path = dir.join(".git");
+        match fs::metadata(&git_path) {
+            Ok(metadata) if metadata.is_file() => {
+                let gitdir = parse_git_file(&git_path).ok()?;
+                extract_bare_clone_from_gitdir(&gitdir).ok()?;
+                return Some(dir.to_path_buf());
+            }
+            // A regular repository's work tree isn't a grove worktree, and
+            // anything above it can't be either.
+            Ok(metadata) if metadata.is_dir() => return None,
+            _ => {}
+        }
+    }
+
+    None
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use crate::git::{create_test_repo, project_root};
+
+    #[test]
+    fn finds_worktree_root_from_subdirectory() {
+        let repo = create_test_repo("worktree-root-subdir");
+        let worktree = repo.add_worktree("feature-a");
+        let nested = worktree.join("src").join("deep");
+        fs::create_dir_all(&nested).unwrap();
+
+        let root = find_worktree_root(&nested).unwrap();
+        assert_eq!(root, fs::canonicalize(&worktree).unwrap());
+    }
+
+    #[test]
+    fn returns_none_outside_a_worktree() {
+        let repo = create_test_repo("worktree-root-outside");
+        assert_eq!(find_worktree_root(project_root(&repo.context)), None);
+    }
+}
@@ -1,8 +1,21 @@
 pub mod worktree_manager;
 
 pub use worktree_manager::{
-    add_worktree, branch_exists, clone_bare_repository, discover_repo, find_worktree_by_name,
-    get_default_branch, is_branch_merged, list_worktrees, normalize_tracking_reference_input,
-    project_root, remove_worktree, remove_worktrees, repo_path, sync_branch, tracked_branch_name,
-    RepoContext, DETACHED_HEAD,
+    add_worktree, add_worktree_with, apply_stash, branch_exists, branches_not_on_remote,
+    checkout_branch, clone_bare_repository, commit_signature, commit_time, commits_ahead,
+    current_branch, delete_branch, describe_commit, detach_worktree, dirty_file_counts,
+    discover_repo, find_remote_branch, for_each_worktree, gc_repository, get_default_branch,
+    get_worktree, get_worktree_by, git_dir_info, is_branch_merged, is_stash_entry,
+    last_commit_summary, list_worktrees, list_worktrees_with, move_worktree,
+    normalize_tracking_reference_input, object_counts, open_repo, operation_in_progress,
+    project_root, prune_worktree_metadata, push_branch, rebase_worktree, registered_at, remote_url,
+    remove_worktree, remove_worktrees, remove_worktrees_parallel, repair_worktree, repo_path,
+    reset_worktree, resolve_revision, resolve_revision_in, resolve_worktree, resolve_worktree_by,
+    sync_branch, touched_at, tracked_branch_name, unpushed_commits, upstream_branch,
+    worktree_status, CommitSignature, DirtyCheck, DirtyFileCounts, MatchBy, ObjectCounts,
+    RebaseOutcome, RepoContext, StashOutcome, WorktreeLock, WorktreeLookupError, WorktreeStatus,
+    DETACHED_HEAD,
 };
+
+#[cfg(test)]
+pub use worktree_manager::{create_test_repo, run_test_git, set_fake_git, TestRepo};
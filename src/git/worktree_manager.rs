@@ -3,9 +3,14 @@ use std::env;
 use std::fs;
 use std::path::{Path, PathBuf};
 use std::process::Command;
+use std::sync::Mutex;
 
 use crate::models::Worktree;
-use crate::utils::{discover_bare_clone, get_project_root, trim_trailing_branch_slashes};
+use crate::timing::time;
+use crate::utils::{
+    discover_bare_clone, get_project_root, parallel_map, parse_git_file, redact_credentials,
+    trim_trailing_branch_slashes, GroveDiscoveryError, CASE_INSENSITIVE_FS,
+};
 
 pub const MAIN_BRANCHES: &[&str] = &["main", "master"];
 pub const DETACHED_HEAD: &str = "detached HEAD";
@@ -16,8 +21,9 @@ pub struct RepoContext {
 }
 
 /// Discover the grove repository and return the repo context.
-pub fn discover_repo() -> Result<RepoContext, String> {
-    let bare_clone_path = discover_bare_clone(None).map_err(|e| e.message)?;
+/// The error carries a `DiscoveryErrorKind` so callers can tailor their guidance.
+pub fn discover_repo() -> Result<RepoContext, GroveDiscoveryError> {
+    let bare_clone_path = discover_bare_clone(None)?;
     let project_root = get_project_root(&bare_clone_path);
 
     // Cache the discovered path
@@ -29,6 +35,15 @@ pub fn discover_repo() -> Result<RepoContext, String> {
     })
 }
 
+/// Build a context for an already-located repository, e.g. a regular
+/// (non-grove) repository that `discover_repo` rejects.
+pub fn open_repo(repo_path: &Path, project_root: &Path) -> RepoContext {
+    RepoContext {
+        repo_path: repo_path.to_path_buf(),
+        project_root: project_root.to_path_buf(),
+    }
+}
+
 pub fn repo_path(context: &RepoContext) -> &Path {
     &context.repo_path
 }
@@ -37,8 +52,32 @@ pub fn project_root(context: &RepoContext) -> &Path {
     &context.project_root
 }
 
+#[cfg(test)]
+thread_local! {
+    /// Shell script run in place of git for the current test thread.
+    static FAKE_GIT: std::cell::RefCell<Option<PathBuf>> = const { std::cell::RefCell::new(None) };
+}
+
+#[cfg(test)]
+pub fn set_fake_git(script: Option<PathBuf>) {
+    FAKE_GIT.with(|fake| *fake.borrow_mut() = script);
+}
+
+fn git_command() -> Command {
+    #[cfg(test)]
+    if let Some(script) = FAKE_GIT.with(|fake| fake.borrow().clone()) {
+        let mut command = Command::new("sh");
+        command.arg(script);
+        return command;
+    }
+
+    Command::new("git")
+}
+
+/// Run git in the bare clone. On failure the error is git's stderr, verbatim
+/// apart from surrounding whitespace, so platform-specific causes reach the user.
 fn git_raw(context: &RepoContext, args: &[&str]) -> Result<String, String> {
-    let output = Command::new("git")
+    let output = git_command()
         .args(args)
         .current_dir(&context.repo_path)
         .output()
@@ -48,22 +87,101 @@ fn git_raw(context: &RepoContext, args: &[&str]) -> Result<String, String> {
         Ok(String::from_utf8_lossy(&output.stdout).to_string())
     } else {
         let stderr = String::from_utf8_lossy(&output.stderr);
-        Err(stderr.trim().to_string())
+        let stderr = stderr.trim();
+        if stderr.is_empty() {
+            Err(format!(
+                "git {} exited with {}",
+                args.join(" "),
+                output.status
+            ))
+        } else {
+            Err(stderr.to_string())
+        }
     }
 }
 
+/// Ask git where the repository's git directory is and whether it is bare.
+pub fn git_dir_info(context: &RepoContext) -> Result<(PathBuf, bool), String> {
+    let output = git_raw(
+        context,
+        &["rev-parse", "--absolute-git-dir", "--is-bare-repository"],
+    )?;
+    let mut lines = output.lines();
+    let git_dir = lines
+        .next()
+        .map(|line| PathBuf::from(line.trim()))
+        .ok_or_else(|| "git rev-parse returned no git dir".to_string())?;
+    let is_bare = lines.next().map(str::trim) == Some("true");
+    Ok((git_dir, is_bare))
+}
+
+/// How listed worktrees get their dirty status.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum DirtyCheck {
+    /// `git status` in every worktree, counting submodule changes.
+    Full,
+    /// `git status` that leaves submodule changes out.
+    IgnoreSubmodules,
+    /// No `git status` at all; every worktree reads as clean.
+    Skip,
+}
+
 pub fn list_worktrees(context: &RepoContext) -> Result<Vec<Worktree>, String> {
-    let result = git_raw(context, &["worktree", "list", "--porcelain"])
-        .map_err(|e| format!("Failed to list worktrees: {}", e))?;
+    list_worktrees_with(context, DirtyCheck::Full)
+}
 
-    let partials = parse_worktree_lines(&result);
+/// Like `list_worktrees`, with `dirty_check` deciding how dirty status is found.
+pub fn list_worktrees_with(
+    context: &RepoContext,
+    dirty_check: DirtyCheck,
+) -> Result<Vec<Worktree>, String> {
     let mut worktrees = Vec::new();
-    for partial in partials {
-        worktrees.push(complete_worktree_info(partial));
-    }
+    for_each_worktree(context, dirty_check, |wt| worktrees.push(wt))?;
     Ok(worktrees)
 }
 
+/// Stream worktrees to `on_worktree` as soon as each one's details are filled in,
+/// so callers can start emitting output before every worktree has been inspected.
+pub fn for_each_worktree<F>(
+    context: &RepoContext,
+    dirty_check: DirtyCheck,
+    mut on_worktree: F,
+) -> Result<(), String>
+where
+    F: FnMut(Worktree),
+{
+    let result = time("git worktree list", || {
+        git_raw(context, &["worktree", "list", "--porcelain"])
+    })
+    .map_err(|e| format!("Failed to list worktrees: {}", e))?;
+
+    let mut repository_is_empty = None;
+    for partial in parse_worktree_lines(&result) {
+        let phase = format!(
+            "worktree details {}",
+            partial.path.as_deref().unwrap_or_default()
+        );
+        let mut worktree = time(&phase, || complete_worktree_info(partial, dirty_check));
+        // Before the first commit every branch is unborn, which git reports the
+        // same way as a deleted branch. Treat it as an empty head instead.
+        if worktree.is_dangling
+            && *repository_is_empty.get_or_insert_with(|| is_empty_repository(context))
+        {
+            worktree.head = String::new();
+            worktree.is_dangling = false;
+        }
+        on_worktree(worktree);
+    }
+    Ok(())
+}
+
+/// A repository without any commits, e.g. right after cloning an empty remote.
+fn is_empty_repository(context: &RepoContext) -> bool {
+    git_raw(context, &["rev-list", "-n", "1", "--all"])
+        .map(|output| output.trim().is_empty())
+        .unwrap_or(false)
+}
+
 pub fn branch_exists(context: &RepoContext, branch: &str) -> bool {
     git_raw(
         context,
@@ -159,6 +277,36 @@ pub fn add_worktree(
     branch_name: &str,
     create_branch: bool,
     track: Option<&str>,
+) -> Result<(), String> {
+    add_worktree_with(
+        context,
+        worktree_path,
+        branch_name,
+        create_branch,
+        track,
+        None,
+        None,
+    )
+}
+
+/// A lock taken by `git worktree add --lock`, so `git worktree prune` and
+/// grove leave the worktree alone from the moment it exists.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub struct WorktreeLock<'a> {
+    pub reason: Option<&'a str>,
+}
+
+/// The general form of `add_worktree`. With `start_point`, the new branch is
+/// created there instead of at the bare clone's HEAD and doesn't track it.
+/// With `lock`, the new worktree is locked in the same git call.
+pub fn add_worktree_with(
+    context: &RepoContext,
+    worktree_path: &str,
+    branch_name: &str,
+    create_branch: bool,
+    track: Option<&str>,
+    start_point: Option<&str>,
+    lock: Option<WorktreeLock>,
 ) -> Result<(), String> {
     let normalized_track = match track {
         Some(track_branch) => Some(normalize_tracking_reference_input(track_branch)?),
@@ -175,13 +323,72 @@ pub fn add_worktree(
         branch_name,
         create_branch,
         normalized_track.as_deref(),
+        start_point,
+        lock,
     );
 
-    git_raw(context, &args).map_err(|e| format!("Failed to add worktree: {}", e))?;
-    if let Some(track_branch) = normalized_track.as_deref() {
-        set_branch_upstream(context, branch_name, track_branch)?;
+    // Snapshot what already exists so a failed add only undoes its own work.
+    let branch_existed = branch_exists(context, branch_name);
+    let path_existed = Path::new(worktree_path).exists();
+
+    let result = git_raw(context, &args)
+        .map_err(|e| format!("Failed to add worktree: {}", e))
+        .and_then(|_| match normalized_track.as_deref() {
+            Some(track_branch) => set_branch_upstream(context, branch_name, track_branch),
+            None => Ok(()),
+        });
+
+    if result.is_err() {
+        rollback_add_worktree(
+            context,
+            &normalized_worktree_path,
+            branch_name,
+            branch_existed,
+            path_existed,
+        );
+    }
+    result
+}
+
+/// Undo a partially completed `add_worktree`: unregister and delete the new
+/// worktree directory, and delete the branch if this add created it. Other
+/// worktrees' entries are left alone, even ones `git worktree prune` would drop.
+fn rollback_add_worktree(
+    context: &RepoContext,
+    worktree_path: &str,
+    branch_name: &str,
+    branch_existed: bool,
+    path_existed: bool,
+) {
+    if !path_existed {
+        if git_raw(context, &["worktree", "remove", "--force", worktree_path]).is_err() {
+            remove_admin_dir_for(context, Path::new(worktree_path));
+        }
+        let _ = fs::remove_dir_all(worktree_path);
+    }
+
+    if !branch_existed && branch_exists(context, branch_name) {
+        // update-ref avoids touching the config file, which may be what failed.
+        let branch_ref = format!("refs/heads/{}", branch_name);
+        let _ = git_raw(context, &["update-ref", "-d", &branch_ref]);
+    }
+}
+
+/// Delete the `worktrees/<name>` admin directory registered for
+/// `worktree_path`, if there is one.
+fn remove_admin_dir_for(context: &RepoContext, worktree_path: &Path) {
+    let Ok(entries) = fs::read_dir(context.repo_path.join("worktrees")) else {
+        return;
+    };
+    let git_file = worktree_path.join(".git");
+    for entry in entries.flatten() {
+        let Ok(gitdir) = fs::read_to_string(entry.path().join("gitdir")) else {
+            continue;
+        };
+        if Path::new(gitdir.trim()) == git_file {
+            let _ = fs::remove_dir_all(entry.path());
+        }
     }
-    Ok(())
 }
 
 fn build_add_worktree_args<'a>(
@@ -189,18 +396,29 @@ fn build_add_worktree_args<'a>(
     branch_name: &'a str,
     create_branch: bool,
     track: Option<&'a str>,
+    start_point: Option<&'a str>,
+    lock: Option<WorktreeLock<'a>>,
 ) -> Vec<&'a str> {
     let mut args = vec!["worktree", "add"];
 
+    if let Some(lock) = lock {
+        args.push("--lock");
+        if let Some(reason) = lock.reason {
+            args.extend(["--reason", reason]);
+        }
+    }
+
     if create_branch {
         args.push("-b");
         args.push(branch_name);
         if track.is_some() {
             args.push("--track");
+        } else if start_point.is_some() {
+            args.push("--no-track");
         }
         args.push(worktree_path);
-        if let Some(track_branch) = track {
-            args.push(track_branch);
+        if let Some(start) = track.or(start_point) {
+            args.push(start);
         }
     } else {
         args.push(worktree_path);
@@ -241,6 +459,36 @@ fn ensure_tracking_reference(context: &RepoContext, track_ref: &str) -> Result<(
     }
 }
 
+/// Find `origin/<branch>` for a branch that exists only on the remote. Already
+/// fetched refs are checked first; `query_remote` also asks the remote itself.
+pub fn find_remote_branch(
+    context: &RepoContext,
+    branch: &str,
+    query_remote: bool,
+) -> Option<String> {
+    if branch_exists(context, branch) {
+        return None;
+    }
+
+    let tracking = format!("origin/{}", branch);
+    if reference_exists(context, &format!("refs/remotes/{}", tracking)) {
+        return Some(tracking);
+    }
+
+    let head_ref = format!("refs/heads/{}", branch);
+    if query_remote
+        && git_raw(
+            context,
+            &["ls-remote", "--exit-code", "--heads", "origin", &head_ref],
+        )
+        .is_ok()
+    {
+        return Some(tracking);
+    }
+
+    None
+}
+
 fn reference_exists(context: &RepoContext, reference: &str) -> bool {
     git_raw(context, &["rev-parse", "--verify", reference]).is_ok()
 }
@@ -349,15 +597,19 @@ pub fn remove_worktree(
     Ok(())
 }
 
-pub fn remove_worktrees(
+/// Remove each worktree in turn, calling `on_start` before every removal so
+/// callers can report progress.
+pub fn remove_worktrees<F: FnMut(&Worktree)>(
     context: &RepoContext,
     worktrees: &[Worktree],
     force: bool,
+    mut on_start: F,
 ) -> (Vec<String>, Vec<(String, String)>) {
     let mut removed = Vec::new();
     let mut failed = Vec::new();
 
     for wt in worktrees {
+        on_start(wt);
         match remove_worktree(context, &wt.path, force) {
             Ok(()) => removed.push(wt.path.clone()),
             Err(e) => failed.push((wt.path.clone(), e)),
@@ -367,178 +619,1012 @@ pub fn remove_worktrees(
     (removed, failed)
 }
 
-pub fn get_default_branch(context: &RepoContext) -> Result<String, String> {
-    // Try to get the default branch from the remote HEAD
-    if let Ok(result) = git_raw(context, &["symbolic-ref", "refs/remotes/origin/HEAD"]) {
-        let branch = result.trim().replace("refs/remotes/origin/", "");
-        return Ok(branch);
-    }
+/// Like `remove_worktrees`, but deletes forced worktrees' directories on up to
+/// `jobs` threads. Git's worktree bookkeeping still runs one worktree at a time
+/// behind a lock, and results are reported in input order.
+pub fn remove_worktrees_parallel<F: FnMut(&Worktree) + Send>(
+    context: &RepoContext,
+    worktrees: &[Worktree],
+    force: bool,
+    jobs: usize,
+    on_start: F,
+) -> (Vec<String>, Vec<(String, String)>) {
+    let git_lock = Mutex::new(());
+    let on_start = Mutex::new(on_start);
+
+    let results = parallel_map(worktrees, jobs, |wt| {
+        (on_start.lock().unwrap())(wt);
+
+        // Deleting the files is the slow part and touches nothing shared.
+        // Without --force, git must inspect the worktree first, and locked
+        // worktrees are left for git to refuse.
+        if force && !wt.is_locked {
+            fs::remove_dir_all(&wt.path)
+                .or_else(|e| match e.kind() {
+                    std::io::ErrorKind::NotFound => Ok(()),
+                    _ => Err(e),
+                })
+                .map_err(|e| format!("Failed to remove worktree: {}", e))?;
+        }
+        let _guard = git_lock.lock().unwrap();
+        remove_worktree(context, &wt.path, force)
+    });
 
-    // Fallback: check if main or master exists
-    if branch_exists(context, "main") {
-        return Ok("main".to_string());
-    }
-    if branch_exists(context, "master") {
-        return Ok("master".to_string());
+    let mut removed = Vec::new();
+    let mut failed = Vec::new();
+    for (wt, result) in worktrees.iter().zip(results) {
+        match result {
+            Ok(()) => removed.push(wt.path.clone()),
+            Err(e) => failed.push((wt.path.clone(), e)),
+        }
     }
 
-    Err("Could not determine default branch. Please specify with --branch.".to_string())
+    (removed, failed)
 }
 
-pub fn sync_branch(context: &RepoContext, branch: &str) -> Result<(), String> {
-    git_raw(
-        context,
-        &["fetch", "origin", &format!("{}:{}", branch, branch)],
-    )
-    .map_err(|e| format!("Failed to sync branch '{}': {}", branch, e))?;
-    Ok(())
+/// How many files in a worktree have uncommitted changes, by kind. A file
+/// with both staged and unstaged changes counts towards both.
+#[derive(Debug, Default, Clone, Copy, PartialEq, Eq)]
+pub struct DirtyFileCounts {
+    pub staged: usize,
+    pub unstaged: usize,
+    pub untracked: usize,
 }
 
-pub fn find_worktree_by_name(
-    context: &RepoContext,
-    name: &str,
-) -> Result<Option<Worktree>, String> {
-    let worktrees = list_worktrees(context)?;
-    Ok(match_worktree_by_name(&worktrees, name).cloned())
+pub fn dirty_file_counts(
+    worktree_path: &str,
+    ignore_submodules: bool,
+) -> Result<DirtyFileCounts, String> {
+    let mut args = vec!["status", "--porcelain"];
+    if ignore_submodules {
+        args.push("--ignore-submodules");
+    }
+    let output = Command::new("git")
+        .args(&args)
+        .current_dir(worktree_path)
+        .output()
+        .map_err(|e| format!("Failed to execute git: {}", e))?;
+    if !output.status.success() {
+        return Err(format!(
+            "Failed to read status of {}: {}",
+            worktree_path,
+            String::from_utf8_lossy(&output.stderr).trim()
+        ));
+    }
+    Ok(parse_dirty_file_counts(&String::from_utf8_lossy(
+        &output.stdout,
+    )))
 }
 
-fn match_worktree_by_name<'a>(worktrees: &'a [Worktree], name: &str) -> Option<&'a Worktree> {
-    let normalized_name = trim_trailing_branch_slashes(name);
+/// Count entries in `git status --porcelain` output, whose first two columns
+/// are the index and worktree status of each path.
+fn parse_dirty_file_counts(porcelain: &str) -> DirtyFileCounts {
+    let mut counts = DirtyFileCounts::default();
+    for line in porcelain.lines() {
+        let mut status = line.chars();
+        let (Some(index), Some(worktree)) = (status.next(), status.next()) else {
+            continue;
+        };
+        if index == '?' && worktree == '?' {
+            counts.untracked += 1;
+            continue;
+        }
+        if index != ' ' {
+            counts.staged += 1;
+        }
+        if worktree != ' ' {
+            counts.unstaged += 1;
+        }
+    }
+    counts
+}
 
-    if normalized_name.is_empty() {
-        return None;
+/// A worktree's branch, upstream divergence, and whether it has uncommitted changes.
+#[derive(Debug, Default, Clone, PartialEq, Eq)]
+pub struct WorktreeStatus {
+    /// `None` when HEAD is detached.
+    pub branch: Option<String>,
+    /// The checked-out commit; empty before the first commit.
+    pub head: String,
+    pub upstream: Option<String>,
+    pub ahead: usize,
+    pub behind: usize,
+    pub dirty: bool,
+}
+
+/// Read a worktree's status with a single `git status` call.
+pub fn worktree_status(worktree_path: &str) -> Result<WorktreeStatus, String> {
+    let output = Command::new("git")
+        .args(["status", "--porcelain=v2", "--branch"])
+        .current_dir(worktree_path)
+        .output()
+        .map_err(|e| format!("Failed to execute git: {}", e))?;
+    if !output.status.success() {
+        return Err(format!(
+            "Failed to read status of {}: {}",
+            worktree_path,
+            String::from_utf8_lossy(&output.stderr).trim()
+        ));
     }
+    Ok(parse_worktree_status(&String::from_utf8_lossy(
+        &output.stdout,
+    )))
+}
 
-    // First, try exact branch name match.
-    if let Some(wt) = worktrees.iter().find(|wt| wt.branch == normalized_name) {
-        return Some(wt);
+/// Parse `git status --porcelain=v2 --branch`: `# branch.*` headers followed
+/// by one line per changed or untracked path.
+fn parse_worktree_status(porcelain: &str) -> WorktreeStatus {
+    let mut status = WorktreeStatus::default();
+    for line in porcelain.lines() {
+        let Some(header) = line.strip_prefix("# ") else {
+            status.dirty |= !line.is_empty();
+            continue;
+        };
+        if let Some(oid) = header.strip_prefix("branch.oid ") {
+            if oid != "(initial)" {
+                status.head = oid.to_string();
+            }
+        } else if let Some(head) = header.strip_prefix("branch.head ") {
+            if head != "(detached)" {
+                status.branch = Some(head.to_string());
+            }
+        } else if let Some(upstream) = header.strip_prefix("branch.upstream ") {
+            status.upstream = Some(upstream.to_string());
+        } else if let Some(counts) = header.strip_prefix("branch.ab ") {
+            for count in counts.split_whitespace() {
+                if let Some(n) = count.strip_prefix('+') {
+                    status.ahead = n.parse().unwrap_or(0);
+                } else if let Some(n) = count.strip_prefix('-') {
+                    status.behind = n.parse().unwrap_or(0);
+                }
+            }
+        }
     }
+    status
+}
 
-    // Try matching by directory name.
-    if let Some(wt) = worktrees.iter().find(|wt| {
-        Path::new(&wt.path)
-            .file_name()
-            .and_then(|n| n.to_str())
-            .map(|n| n == normalized_name)
-            .unwrap_or(false)
-    }) {
-        return Some(wt);
+/// Who authored and committed a commit, as recorded in the commit object.
+pub struct CommitSignature {
+    pub author_name: String,
+    pub author_email: String,
+    pub committer_name: String,
+    pub committer_email: String,
+}
+
+pub fn commit_signature(context: &RepoContext, rev: &str) -> Result<CommitSignature, String> {
+    let output = git_raw(
+        context,
+        &["log", "-1", "--format=%an%x00%ae%x00%cn%x00%ce", rev, "--"],
+    )
+    .map_err(|e| format!("Failed to read commit '{}': {}", rev, e))?;
+    let fields: Vec<&str> = output.trim_end_matches('\n').split('\0').collect();
+    match fields.as_slice() {
+        [author_name, author_email, committer_name, committer_email] => Ok(CommitSignature {
+            author_name: author_name.to_string(),
+            author_email: author_email.to_string(),
+            committer_name: committer_name.to_string(),
+            committer_email: committer_email.to_string(),
+        }),
+        _ => Err(format!("Unexpected commit format for '{}'", rev)),
     }
+}
 
-    // Try partial branch name match (suffix matching).
-    worktrees
-        .iter()
-        .find(|wt| wt.branch.ends_with(&format!("/{}", normalized_name)))
+/// When a commit was committed (the committer date, not the author date).
+pub fn commit_time(context: &RepoContext, rev: &str) -> Result<DateTime<Utc>, String> {
+    let output = git_raw(context, &["log", "-1", "--format=%ct", rev, "--"])
+        .map_err(|e| format!("Failed to read commit '{}': {}", rev, e))?;
+    output
+        .trim()
+        .parse::<i64>()
+        .ok()
+        .and_then(|seconds| DateTime::from_timestamp(seconds, 0))
+        .ok_or_else(|| format!("Unexpected commit date for '{}'", rev))
 }
 
-struct PartialWorktree {
-    path: Option<String>,
-    head: Option<String>,
-    branch: Option<String>,
-    is_locked: bool,
-    is_prunable: bool,
-    is_bare: bool,
+/// When the worktree at `worktree_path` was registered with git, taken from
+/// its admin directory under `<bare>/worktrees/`, which `git worktree add`
+/// creates. Uses the directory's creation time where the filesystem records
+/// one. Otherwise it falls back to the mtime of the `gitdir` file inside it:
+/// git writes that file at add time, whereas the directory's own mtime moves
+/// whenever git rewrites `HEAD` or `index` there. `git worktree move` and
+/// `repair` rewrite `gitdir` too, so on such filesystems a moved or repaired
+/// worktree sorts as if it were added then. None when the worktree has no
+/// admin directory.
+pub fn registered_at(worktree_path: &Path) -> Option<DateTime<Utc>> {
+    let gitdir = parse_git_file(&worktree_path.join(".git")).ok()?;
+    let admin_dir = worktree_path.join(gitdir);
+    let created = fs::metadata(&admin_dir).ok()?.created();
+    created
+        .or_else(|_| gitdir_written_at(&admin_dir))
+        .ok()
+        // Full precision: worktrees added in the same second still sort.
+        .map(DateTime::<Utc>::from)
 }
 
-fn parse_worktree_lines(output: &str) -> Vec<PartialWorktree> {
-    let mut worktrees = Vec::new();
-    let mut current = PartialWorktree {
-        path: None,
-        head: None,
-        branch: None,
-        is_locked: false,
-        is_prunable: false,
-        is_bare: false,
-    };
+fn gitdir_written_at(admin_dir: &Path) -> std::io::Result<std::time::SystemTime> {
+    fs::metadata(admin_dir.join("gitdir"))?.modified()
+}
 
-    for line in output.trim().lines() {
-        if let Some(path) = line.strip_prefix("worktree ") {
-            if current.path.is_some() && !current.is_bare {
-                worktrees.push(current);
-            }
-            current = PartialWorktree {
-                path: Some(path.to_string()),
-                head: None,
-                branch: None,
-                is_locked: false,
-                is_prunable: false,
-                is_bare: false,
-            };
-        } else if let Some(head) = line.strip_prefix("HEAD ") {
-            current.head = Some(head.to_string());
-        } else if let Some(branch) = line.strip_prefix("branch ") {
-            current.branch = Some(branch.replace("refs/heads/", ""));
-        } else if line == "detached" {
-            current.branch = Some(DETACHED_HEAD.to_string());
-        } else if line == "locked" {
-            current.is_locked = true;
-        } else if line == "prunable" {
-            current.is_prunable = true;
-        } else if line == "bare" {
-            current.is_bare = true;
-        }
+/// When the worktree at `worktree_path` was last touched on disk: the newer of
+/// the directory's own mtime and its git index, which git rewrites on
+/// checkout, staging, and status refreshes.
+pub fn touched_at(worktree_path: &Path) -> Option<DateTime<Utc>> {
+    let mut paths = vec![worktree_path.to_path_buf()];
+    if let Ok(gitdir) = parse_git_file(&worktree_path.join(".git")) {
+        paths.push(worktree_path.join(gitdir).join("index"));
     }
+    paths
+        .iter()
+        .filter_map(|path| fs::metadata(path).ok()?.modified().ok())
+        .filter_map(system_time_to_datetime)
+        .max()
+}
 
-    if current.path.is_some() && !current.is_bare {
-        worktrees.push(current);
-    }
+/// Resolve a branch, tag, or other revision to the full hash of its commit.
+pub fn resolve_revision(context: &RepoContext, rev: &str) -> Result<String, String> {
+    let commit = format!("{}^{{commit}}", rev);
+    git_raw(context, &["rev-parse", "--verify", "--quiet", &commit])
+        .map(|output| output.trim().to_string())
+        .map_err(|_| format!("Unknown revision '{}'", rev))
+}
 
-    worktrees
+/// Like `resolve_revision`, but resolved inside the worktree at
+/// `worktree_path`, so `HEAD~1` and `@{u}` refer to that worktree's branch.
+pub fn resolve_revision_in(
+    context: &RepoContext,
+    worktree_path: &str,
+    rev: &str,
+) -> Result<String, String> {
+    let normalized_worktree_path = normalize_path_for_git(worktree_path);
+    let commit = format!("{}^{{commit}}", rev);
+    git_raw(
+        context,
+        &[
+            "-C",
+            &normalized_worktree_path,
+            "rev-parse",
+            "--verify",
+            "--quiet",
+            &commit,
+        ],
+    )
+    .map(|output| output.trim().to_string())
+    .map_err(|_| format!("Unknown revision '{}'", rev))
 }
 
-fn complete_worktree_info(partial: PartialWorktree) -> Worktree {
-    let path = partial.path.unwrap_or_default();
-    let branch = partial.branch.unwrap_or_default();
-    let head = partial.head.unwrap_or_default();
+/// How many commits reachable from `rev` are not reachable from `base`.
+pub fn commits_ahead(context: &RepoContext, base: &str, rev: &str) -> Result<usize, String> {
+    let range = format!("{}..{}", base, rev);
+    let output = git_raw(context, &["rev-list", "--count", &range])
+        .map_err(|e| format!("Failed to compare {} with {}: {}", rev, base, e))?;
+    output
+        .trim()
+        .parse()
+        .map_err(|_| format!("Unexpected commit count for {}", range))
+}
 
-    let is_main = MAIN_BRANCHES.contains(&branch.as_str());
+/// One-line summary of a commit: abbreviated hash, subject, and relative date.
+pub fn last_commit_summary(context: &RepoContext, rev: &str) -> Result<String, String> {
+    let output = git_raw(context, &["log", "-1", "--format=%h %s (%cr)", rev, "--"])
+        .map_err(|e| format!("Failed to read commit '{}': {}", rev, e))?;
+    Ok(output.trim().to_string())
+}
 
-    // Check if worktree is dirty
-    let is_dirty = Command::new("git")
-        .args(["status", "--porcelain"])
-        .current_dir(&path)
-        .output()
-        .map(|output| !output.stdout.is_empty())
-        .unwrap_or(false);
+/// The nearest tag describing `rev`: the tag itself when one points at it,
+/// otherwise e.g. `v2.1.0-3-g1a2b3c4`. `None` when no tag is reachable.
+pub fn describe_commit(context: &RepoContext, rev: &str) -> Option<String> {
+    let output = git_raw(context, &["describe", "--tags", rev]).ok()?;
+    let description = output.trim();
+    (!description.is_empty()).then(|| description.to_string())
+}
 
-    // Try to get creation time from filesystem with Unix fallbacks.
-    let created_at = fs::metadata(&path)
-        .ok()
-        .and_then(|meta| metadata_created_at(&meta))
-        .unwrap_or_else(|| DateTime::from_timestamp(0, 0).unwrap());
+/// The upstream a local branch tracks, e.g. `origin/feature`.
+pub fn upstream_branch(context: &RepoContext, branch: &str) -> Option<String> {
+    let upstream = format!("{}@{{upstream}}", branch);
+    let output = git_raw(
+        context,
+        &[
+            "rev-parse",
+            "--abbrev-ref",
+            "--symbolic-full-name",
+            &upstream,
+        ],
+    )
+    .ok()?;
+    let upstream = output.trim();
+    (!upstream.is_empty()).then(|| upstream.to_string())
+}
 
-    Worktree {
-        path,
-        branch,
-        head,
-        created_at,
-        is_dirty,
-        is_locked: partial.is_locked,
-        is_prunable: partial.is_prunable,
-        is_main,
+/// How many commits `branch` has that its upstream doesn't. `None` when the
+/// branch has no upstream or the comparison fails.
+pub fn unpushed_commits(context: &RepoContext, branch: &str) -> Option<usize> {
+    let upstream = upstream_branch(context, branch)?;
+    commits_ahead(context, &upstream, branch).ok()
+}
+
+/// Local branches with commits that no remote-tracking ref has, and how many.
+/// A branch without an upstream counts too: its commits exist only here.
+pub fn branches_not_on_remote(context: &RepoContext) -> Result<Vec<(String, usize)>, String> {
+    let output = git_raw(
+        context,
+        &["for-each-ref", "--format=%(refname:short)", "refs/heads"],
+    )
+    .map_err(|e| format!("Failed to list branches: {}", e))?;
+    let mut unpushed = Vec::new();
+    for branch in output.lines().filter(|line| !line.is_empty()) {
+        let branch_ref = format!("refs/heads/{}", branch);
+        let count: usize = git_raw(
+            context,
+            &["rev-list", "--count", &branch_ref, "--not", "--remotes"],
+        )
+        .map_err(|e| format!("Failed to compare {} with the remotes: {}", branch, e))?
+        .trim()
+        .parse()
+        .map_err(|_| format!("Unexpected commit count for {}", branch))?;
+        if count > 0 {
+            unpushed.push((branch.to_string(), count));
+        }
     }
+    Ok(unpushed)
 }
 
-fn system_time_to_datetime(system_time: std::time::SystemTime) -> Option<DateTime<Utc>> {
-    let duration = system_time.duration_since(std::time::UNIX_EPOCH).ok()?;
-    Utc.timestamp_opt(duration.as_secs() as i64, 0).single()
+/// Object totals from `git count-objects -v`. Sizes are in KiB, as git reports them.
+#[derive(Debug, Default, Clone, Copy, PartialEq, Eq)]
+pub struct ObjectCounts {
+    pub loose: u64,
+    pub loose_size_kb: u64,
+    pub packs: u64,
+    pub pack_size_kb: u64,
 }
 
-fn metadata_created_at(meta: &fs::Metadata) -> Option<DateTime<Utc>> {
-    if let Ok(st) = meta.created() {
-        return system_time_to_datetime(st);
-    }
+pub fn object_counts(context: &RepoContext) -> Result<ObjectCounts, String> {
+    let output = git_raw(context, &["count-objects", "-v"])
+        .map_err(|e| format!("Failed to count objects: {}", e))?;
+    Ok(parse_object_counts(&output))
+}
 
-    #[cfg(unix)]
-    {
-        use std::os::unix::fs::MetadataExt;
-        let ctime = meta.ctime();
-        if ctime > 0 {
-            return Utc.timestamp_opt(ctime, 0).single();
+fn parse_object_counts(output: &str) -> ObjectCounts {
+    let mut counts = ObjectCounts::default();
+    for line in output.lines() {
+        let Some((key, value)) = line.split_once(':') else {
+            continue;
+        };
+        let value = value.trim().parse().unwrap_or(0);
+        match key.trim() {
+            "count" => counts.loose = value,
+            "size" => counts.loose_size_kb = value,
+            "packs" => counts.packs = value,
+            "size-pack" => counts.pack_size_kb = value,
+            _ => {}
         }
-        let mtime = meta.mtime();
-        if mtime > 0 {
-            return Utc.timestamp_opt(mtime, 0).single();
+    }
+    counts
+}
+
+/// Drop administrative files for worktrees whose directories are gone.
+pub fn prune_worktree_metadata(context: &RepoContext) -> Result<(), String> {
+    git_raw(context, &["worktree", "prune"])
+        .map(|_| ())
+        .map_err(|e| format!("git worktree prune failed: {}", e))
+}
+
+/// Restore the links between the worktree at `worktree_path` and the bare
+/// clone, e.g. a deleted or stale `.git` file.
+pub fn repair_worktree(context: &RepoContext, worktree_path: &str) -> Result<(), String> {
+    let normalized_path = normalize_path_for_git(worktree_path);
+    git_raw(context, &["worktree", "repair", &normalized_path])
+        .map(|_| ())
+        .map_err(|e| format!("Failed to repair worktree '{}': {}", worktree_path, e))
+}
+
+/// Push `branch` to origin from the worktree at `worktree_path` and make
+/// `origin/<branch>` its upstream. Credentials in the error are redacted.
+pub fn push_branch(context: &RepoContext, worktree_path: &str, branch: &str) -> Result<(), String> {
+    git_raw(
+        context,
+        &[
+            "-C",
+            &normalize_path_for_git(worktree_path),
+            "push",
+            "-q",
+            "-u",
+            "origin",
+            branch,
+        ],
+    )
+    .map(|_| ())
+    .map_err(|e| {
+        format!(
+            "Failed to push '{}' to origin: {}",
+            branch,
+            redact_credentials(&e)
+        )
+    })
+}
+
+/// Detach HEAD in a worktree at its current commit, keeping any local changes.
+pub fn detach_worktree(context: &RepoContext, worktree_path: &str) -> Result<(), String> {
+    let normalized_path = normalize_path_for_git(worktree_path);
+    git_raw(
+        context,
+        &["-C", &normalized_path, "checkout", "-q", "--detach"],
+    )
+    .map(|_| ())
+    .map_err(|e| format!("Failed to detach worktree '{}': {}", worktree_path, e))
+}
+
+/// Run `git gc` in the bare clone. `prune` is passed through as `--prune=<date>`.
+pub fn gc_repository(
+    context: &RepoContext,
+    aggressive: bool,
+    prune: Option<&str>,
+) -> Result<(), String> {
+    let prune_arg = prune.map(|date| format!("--prune={}", date));
+    let mut args = vec!["gc", "--quiet"];
+    if aggressive {
+        args.push("--aggressive");
+    }
+    if let Some(prune_arg) = prune_arg.as_deref() {
+        args.push(prune_arg);
+    }
+    git_raw(context, &args)
+        .map(|_| ())
+        .map_err(|e| format!("git gc failed: {}", e))
+}
+
+/// Describe a git operation that looks unfinished in the bare clone or any
+/// worktree: a held lock, or a rebase, merge, cherry-pick, revert, or bisect.
+pub fn operation_in_progress(context: &RepoContext) -> Option<String> {
+    const MARKERS: [(&str, &str); 9] = [
+        ("index.lock", "an index update"),
+        ("HEAD.lock", "a HEAD update"),
+        ("packed-refs.lock", "a ref update"),
+        ("rebase-merge", "a rebase"),
+        ("rebase-apply", "a rebase"),
+        ("MERGE_HEAD", "a merge"),
+        ("CHERRY_PICK_HEAD", "a cherry-pick"),
+        ("REVERT_HEAD", "a revert"),
+        ("BISECT_LOG", "a bisect"),
+    ];
+
+    let mut git_dirs = vec![(None, context.repo_path.clone())];
+    if let Ok(entries) = fs::read_dir(context.repo_path.join("worktrees")) {
+        for entry in entries.flatten() {
+            let name = entry.file_name().to_string_lossy().to_string();
+            git_dirs.push((Some(name), entry.path()));
+        }
+    }
+
+    for (worktree, git_dir) in git_dirs {
+        for (marker, operation) in MARKERS {
+            if git_dir.join(marker).exists() {
+                return Some(match worktree {
+                    Some(name) => format!("{} in worktree '{}'", operation, name),
+                    None => format!("{} in the bare clone", operation),
+                });
+            }
+        }
+    }
+    None
+}
+
+/// The URL of the `origin` remote, if one is configured.
+pub fn remote_url(context: &RepoContext) -> Option<String> {
+    let output = git_raw(context, &["config", "--get", "remote.origin.url"]).ok()?;
+    let url = output.trim();
+    (!url.is_empty()).then(|| url.to_string())
+}
+
+/// The branch the repository's own HEAD points at, if any.
+pub fn current_branch(context: &RepoContext) -> Option<String> {
+    let result = git_raw(context, &["symbolic-ref", "--short", "HEAD"]).ok()?;
+    let branch = result.trim();
+    (!branch.is_empty()).then(|| branch.to_string())
+}
+
+/// Create a local branch pointing at `start_point` (a branch, tag, or commit).
+#[allow(dead_code)]
+pub fn create_branch(context: &RepoContext, branch: &str, start_point: &str) -> Result<(), String> {
+    git_raw(context, &["branch", "--no-track", branch, start_point])
+        .map_err(|e| format!("Failed to create branch '{}': {}", branch, e))?;
+    Ok(())
+}
+
+/// Delete a local branch. Unless `force` is set, git refuses branches that
+/// aren't merged into their upstream or the repository's HEAD.
+pub fn delete_branch(context: &RepoContext, branch: &str, force: bool) -> Result<(), String> {
+    let flag = if force { "-D" } else { "-d" };
+    git_raw(context, &["branch", flag, branch])
+        .map_err(|e| format!("Failed to delete branch '{}': {}", branch, e))?;
+    Ok(())
+}
+
+/// Check out `branch` inside an existing worktree directory.
+pub fn checkout_branch(
+    context: &RepoContext,
+    worktree_path: &str,
+    branch: &str,
+) -> Result<(), String> {
+    let normalized_worktree_path = normalize_path_for_git(worktree_path);
+    git_raw(
+        context,
+        &["-C", &normalized_worktree_path, "checkout", branch],
+    )
+    .map_err(|e| format!("Failed to check out '{}': {}", branch, e))?;
+    Ok(())
+}
+
+/// Hard-reset the worktree at `worktree_path` to `rev`, discarding staged and
+/// unstaged changes. Untracked files are left alone.
+pub fn reset_worktree(context: &RepoContext, worktree_path: &str, rev: &str) -> Result<(), String> {
+    let normalized_worktree_path = normalize_path_for_git(worktree_path);
+    git_raw(
+        context,
+        &[
+            "-C",
+            &normalized_worktree_path,
+            "reset",
+            "--hard",
+            "-q",
+            rev,
+        ],
+    )
+    .map_err(|e| format!("Failed to reset to '{}': {}", rev, e))?;
+    Ok(())
+}
+
+/// How rebasing a worktree onto a base ended.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum RebaseOutcome {
+    Rebased,
+    UpToDate,
+    /// The rebase stopped on conflicts and was left in progress for manual resolution.
+    Conflict,
+}
+
+/// Rebase the branch checked out in `worktree_path` onto `onto`.
+pub fn rebase_worktree(
+    context: &RepoContext,
+    worktree_path: &str,
+    onto: &str,
+) -> Result<RebaseOutcome, String> {
+    let normalized_worktree_path = normalize_path_for_git(worktree_path);
+    let path = normalized_worktree_path.as_str();
+
+    if git_raw(
+        context,
+        &["-C", path, "merge-base", "--is-ancestor", onto, "HEAD"],
+    )
+    .is_ok()
+    {
+        return Ok(RebaseOutcome::UpToDate);
+    }
+
+    match git_raw(context, &["-C", path, "rebase", onto]) {
+        Ok(_) => Ok(RebaseOutcome::Rebased),
+        Err(e) => {
+            // REBASE_HEAD only exists while a rebase is stopped on a conflict.
+            if git_raw(
+                context,
+                &["-C", path, "rev-parse", "--verify", "-q", "REBASE_HEAD"],
+            )
+            .is_ok()
+            {
+                Ok(RebaseOutcome::Conflict)
+            } else {
+                Err(format!("Failed to rebase onto '{}': {}", onto, e))
+            }
+        }
+    }
+}
+
+/// How applying a stash to a worktree ended.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum StashOutcome {
+    Applied,
+    /// The stash applied with conflicts, which were left in the worktree. A
+    /// popped stash is kept when this happens.
+    Conflict,
+}
+
+/// Whether `stash` (e.g. `stash@{2}`) names an entry on the stash list, as
+/// opposed to a branch or any other commit.
+pub fn is_stash_entry(context: &RepoContext, stash: &str) -> bool {
+    let Ok(commit) = resolve_revision(context, stash) else {
+        return false;
+    };
+    git_raw(
+        context,
+        &["log", "--walk-reflogs", "--format=%H", "refs/stash"],
+    )
+    .map(|output| output.lines().any(|line| line == commit))
+    .unwrap_or(false)
+}
+
+/// Apply `stash` in `worktree_path`, dropping it afterwards when `pop` is set.
+pub fn apply_stash(
+    context: &RepoContext,
+    worktree_path: &str,
+    stash: &str,
+    pop: bool,
+) -> Result<StashOutcome, String> {
+    let normalized_worktree_path = normalize_path_for_git(worktree_path);
+    let path = normalized_worktree_path.as_str();
+    let action = if pop { "pop" } else { "apply" };
+
+    match git_raw(context, &["-C", path, "stash", action, stash]) {
+        Ok(_) => Ok(StashOutcome::Applied),
+        Err(e) => {
+            let unmerged = git_raw(
+                context,
+                &["-C", path, "diff", "--name-only", "--diff-filter=U"],
+            )
+            .unwrap_or_default();
+            if unmerged.trim().is_empty() {
+                Err(format!("Failed to apply {}: {}", stash, e))
+            } else {
+                Ok(StashOutcome::Conflict)
+            }
+        }
+    }
+}
+
+/// Move a worktree with `git worktree move`, which rewrites both the worktree's
+/// `.git` link and the bare clone's gitdir pointer.
+pub fn move_worktree(context: &RepoContext, from: &Path, to: &Path) -> Result<(), String> {
+    let from = normalize_path_for_git(&from.to_string_lossy());
+    let to = normalize_path_for_git(&to.to_string_lossy());
+    git_raw(context, &["worktree", "move", &from, &to])
+        .map_err(|e| format!("Failed to move worktree {} to {}: {}", from, to, e))?;
+    Ok(())
+}
+
+pub fn get_default_branch(context: &RepoContext) -> Result<String, String> {
+    // Try to get the default branch from the remote HEAD
+    if let Ok(result) = git_raw(context, &["symbolic-ref", "refs/remotes/origin/HEAD"]) {
+        let branch = result.trim().replace("refs/remotes/origin/", "");
+        return Ok(branch);
+    }
+
+    // A bare clone's own HEAD points at the branch that was checked out on origin.
+    // In an empty repository that branch is unborn, but it is still the default.
+    if let Some(branch) = current_branch(context) {
+        if branch_exists(context, &branch) || is_empty_repository(context) {
+            return Ok(branch);
+        }
+    }
+
+    // Fallback: check if main or master exists
+    if branch_exists(context, "main") {
+        return Ok("main".to_string());
+    }
+    if branch_exists(context, "master") {
+        return Ok("master".to_string());
+    }
+
+    Err("Could not determine default branch. Please specify with --branch.".to_string())
+}
+
+pub fn sync_branch(context: &RepoContext, branch: &str) -> Result<(), String> {
+    git_raw(
+        context,
+        &["fetch", "origin", &format!("{}:{}", branch, branch)],
+    )
+    .map_err(|e| format!("Failed to sync branch '{}': {}", branch, e))?;
+    Ok(())
+}
+
+/// Why a worktree query could not be resolved to a single worktree.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum WorktreeLookupError {
+    /// No worktree matched the query.
+    NotFound(String),
+    /// Several worktrees matched the query at the same precedence tier.
+    Ambiguous(String, Vec<String>),
+    /// The worktree list could not be read.
+    List(String),
+}
+
+impl std::fmt::Display for WorktreeLookupError {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self {
+            WorktreeLookupError::NotFound(query) => write!(
+                f,
+                "Worktree '{}' not found. Use 'grove list' to see available worktrees.",
+                query
+            ),
+            WorktreeLookupError::Ambiguous(query, paths) => write!(
+                f,
+                "'{}' matches multiple worktrees: {}. Use a more specific name or the full path.",
+                query,
+                paths.join(", ")
+            ),
+            WorktreeLookupError::List(e) => write!(f, "{}", e),
+        }
+    }
+}
+
+impl std::error::Error for WorktreeLookupError {}
+
+/// Which parts of a worktree a query may match.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum MatchBy {
+    /// Path, directory name, or branch, falling back to substrings.
+    Any,
+    /// Only the exact branch name.
+    Branch,
+    /// Only the full path or the directory name.
+    Path,
+    /// Path, directory name, or branch, with no substring fallback. For
+    /// commands that delete or create, where a near miss must not count.
+    Exact,
+}
+
+impl MatchBy {
+    /// `self` without the substring fallback.
+    pub fn exact(self) -> MatchBy {
+        match self {
+            MatchBy::Any => MatchBy::Exact,
+            by => by,
+        }
+    }
+}
+
+/// Resolve a user-provided name, branch, or path to a single worktree.
+pub fn get_worktree(context: &RepoContext, query: &str) -> Result<Worktree, WorktreeLookupError> {
+    get_worktree_by(context, query, MatchBy::Any)
+}
+
+/// Like `get_worktree`, but only matching what `by` allows.
+pub fn get_worktree_by(
+    context: &RepoContext,
+    query: &str,
+    by: MatchBy,
+) -> Result<Worktree, WorktreeLookupError> {
+    let worktrees = list_worktrees(context).map_err(WorktreeLookupError::List)?;
+    resolve_worktree_by(&worktrees, query, by).cloned()
+}
+
+/// Match `query` against `worktrees`, trying each tier in order and stopping
+/// at the first tier with any match: exact path, exact directory name, exact
+/// branch, then substring of the branch or directory name. On case-insensitive
+/// filesystems, case-insensitive path, directory, and branch matches are tried
+/// before substrings, and substrings ignore case.
+pub fn resolve_worktree<'a>(
+    worktrees: &'a [Worktree],
+    query: &str,
+) -> Result<&'a Worktree, WorktreeLookupError> {
+    resolve_worktree_by(worktrees, query, MatchBy::Any)
+}
+
+/// Like `resolve_worktree`, but skipping the tiers `by` rules out. Only
+/// `MatchBy::Any` falls back to substrings.
+pub fn resolve_worktree_by<'a>(
+    worktrees: &'a [Worktree],
+    query: &str,
+    by: MatchBy,
+) -> Result<&'a Worktree, WorktreeLookupError> {
+    resolve_worktree_with(worktrees, query, by, CASE_INSENSITIVE_FS)
+}
+
+fn resolve_worktree_with<'a>(
+    worktrees: &'a [Worktree],
+    query: &str,
+    by: MatchBy,
+    case_insensitive: bool,
+) -> Result<&'a Worktree, WorktreeLookupError> {
+    let trimmed = query.trim();
+    let normalized_path = trimmed.trim_end_matches(['/', '\\']);
+    let normalized_name = trim_trailing_branch_slashes(trimmed);
+
+    if normalized_name.is_empty() {
+        return Err(WorktreeLookupError::NotFound(query.to_string()));
+    }
+
+    let lowercase_name = normalized_name.to_lowercase();
+    let contains = |haystack: &str| {
+        if case_insensitive {
+            haystack.to_lowercase().contains(&lowercase_name)
+        } else {
+            haystack.contains(normalized_name)
+        }
+    };
+
+    let by_path = by != MatchBy::Branch;
+    let by_branch = by != MatchBy::Path;
+    let tiers: [&dyn Fn(&Worktree) -> bool; 5] = [
+        &|wt| {
+            by_path
+                && !normalized_path.is_empty()
+                && wt.path.trim_end_matches(['/', '\\']) == normalized_path
+        },
+        &|wt| by_path && worktree_dir_name(wt) == Some(normalized_name),
+        &|wt| by_branch && wt.branch == normalized_name,
+        &|wt| {
+            case_insensitive
+                && ((by_path
+                    && ((!normalized_path.is_empty()
+                        && wt
+                            .path
+                            .trim_end_matches(['/', '\\'])
+                            .eq_ignore_ascii_case(normalized_path))
+                        || worktree_dir_name(wt)
+                            .map(|n| n.eq_ignore_ascii_case(normalized_name))
+                            .unwrap_or(false)))
+                    || (by_branch && wt.branch.eq_ignore_ascii_case(normalized_name)))
+        },
+        &|wt| {
+            by == MatchBy::Any
+                && (contains(&wt.branch) || worktree_dir_name(wt).map(contains).unwrap_or(false))
+        },
+    ];
+
+    for tier in tiers {
+        let matches: Vec<&Worktree> = worktrees.iter().filter(|wt| tier(wt)).collect();
+        match matches.len() {
+            0 => continue,
+            1 => return Ok(matches[0]),
+            _ => {
+                return Err(WorktreeLookupError::Ambiguous(
+                    normalized_name.to_string(),
+                    matches.iter().map(|wt| wt.path.clone()).collect(),
+                ))
+            }
+        }
+    }
+
+    Err(WorktreeLookupError::NotFound(normalized_name.to_string()))
+}
+
+fn worktree_dir_name(worktree: &Worktree) -> Option<&str> {
+    Path::new(&worktree.path)
+        .file_name()
+        .and_then(|n| n.to_str())
+}
+
+struct PartialWorktree {
+    path: Option<String>,
+    head: Option<String>,
+    branch: Option<String>,
+    is_locked: bool,
+    lock_reason: Option<String>,
+    is_prunable: bool,
+    is_bare: bool,
+}
+
+fn parse_worktree_lines(output: &str) -> Vec<PartialWorktree> {
+    let mut worktrees = Vec::new();
+    let mut current = PartialWorktree {
+        path: None,
+        head: None,
+        branch: None,
+        is_locked: false,
+        lock_reason: None,
+        is_prunable: false,
+        is_bare: false,
+    };
+
+    for line in output.trim().lines() {
+        if let Some(path) = line.strip_prefix("worktree ") {
+            if current.path.is_some() && !current.is_bare {
+                worktrees.push(current);
+            }
+            current = PartialWorktree {
+                path: Some(path.to_string()),
+                head: None,
+                branch: None,
+                is_locked: false,
+                lock_reason: None,
+                is_prunable: false,
+                is_bare: false,
+            };
+        } else if let Some(head) = line.strip_prefix("HEAD ") {
+            current.head = Some(head.to_string());
+        } else if let Some(branch) = line.strip_prefix("branch ") {
+            current.branch = Some(branch.replace("refs/heads/", ""));
+        } else if line == "detached" {
+            current.branch = Some(DETACHED_HEAD.to_string());
+        } else if line == "locked" || line.starts_with("locked ") {
+            // Both may be followed by a reason, e.g. `prunable gitdir file
+            // points to non-existent location`.
+            current.is_locked = true;
+            current.lock_reason = line
+                .strip_prefix("locked ")
+                .map(|reason| reason.to_string());
+        } else if line == "prunable" || line.starts_with("prunable ") {
+            current.is_prunable = true;
+        } else if line == "bare" {
+            current.is_bare = true;
+        }
+    }
+
+    if current.path.is_some() && !current.is_bare {
+        worktrees.push(current);
+    }
+
+    worktrees
+}
+
+fn complete_worktree_info(partial: PartialWorktree, dirty_check: DirtyCheck) -> Worktree {
+    let path = partial.path.unwrap_or_default();
+    let branch = partial.branch.unwrap_or_default();
+    let head = partial.head.unwrap_or_default();
+
+    let is_main = MAIN_BRANCHES.contains(&branch.as_str());
+    let is_dangling = is_dangling_branch(&branch, &head);
+
+    let is_dirty = match dirty_check {
+        DirtyCheck::Skip => false,
+        DirtyCheck::Full | DirtyCheck::IgnoreSubmodules => {
+            let mut status_args = vec!["status", "--porcelain"];
+            if dirty_check == DirtyCheck::IgnoreSubmodules {
+                status_args.push("--ignore-submodules");
+            }
+            git_command()
+                .args(&status_args)
+                .current_dir(&path)
+                .output()
+                .map(|output| !output.stdout.is_empty())
+                .unwrap_or(false)
+        }
+    };
+
+    // Try to get creation time from filesystem with Unix fallbacks.
+    let created_at = fs::metadata(&path)
+        .ok()
+        .and_then(|meta| metadata_created_at(&meta))
+        .unwrap_or_else(|| DateTime::from_timestamp(0, 0).unwrap());
+
+    Worktree {
+        path,
+        branch,
+        head,
+        created_at,
+        is_dirty,
+        is_locked: partial.is_locked,
+        lock_reason: partial.lock_reason,
+        is_prunable: partial.is_prunable,
+        is_main,
+        is_dangling,
+    }
+}
+
+/// git reports an all-zero HEAD when a worktree's branch ref has been deleted.
+fn is_dangling_branch(branch: &str, head: &str) -> bool {
+    if branch.is_empty() || branch == DETACHED_HEAD {
+        return false;
+    }
+    head.is_empty() || head.chars().all(|c| c == '0')
+}
+
+fn system_time_to_datetime(system_time: std::time::SystemTime) -> Option<DateTime<Utc>> {
+    let duration = system_time.duration_since(std::time::UNIX_EPOCH).ok()?;
+    Utc.timestamp_opt(duration.as_secs() as i64, 0).single()
+}
+
+fn metadata_created_at(meta: &fs::Metadata) -> Option<DateTime<Utc>> {
+    if let Ok(st) = meta.created() {
+        return system_time_to_datetime(st);
+    }
+
+    #[cfg(unix)]
+    {
+        use std::os::unix::fs::MetadataExt;
+        let ctime = meta.ctime();
+        if ctime > 0 {
+            return Utc.timestamp_opt(ctime, 0).single();
+        }
+        let mtime = meta.mtime();
+        if mtime > 0 {
+            return Utc.timestamp_opt(mtime, 0).single();
+        }
+    }
+
+    #[cfg(windows)]
+    {
+        use std::os::windows::fs::MetadataExt;
+        if let Some(created) = filetime_to_datetime(meta.creation_time()) {
+            return Some(created);
         }
     }
 
@@ -552,6 +1638,22 @@ fn metadata_created_at(meta: &fs::Metadata) -> Option<DateTime<Utc>> {
     None
 }
 
+/// Seconds between the Windows FILETIME epoch (1601-01-01) and the Unix epoch.
+#[cfg(any(windows, test))]
+const FILETIME_UNIX_EPOCH_SECONDS: u64 = 11_644_473_600;
+
+/// Convert a raw Windows FILETIME (100ns intervals since 1601-01-01 UTC).
+/// Zero means the filesystem didn't record a time.
+#[cfg(any(windows, test))]
+fn filetime_to_datetime(filetime: u64) -> Option<DateTime<Utc>> {
+    const INTERVALS_PER_SECOND: u64 = 10_000_000;
+    if filetime == 0 {
+        return None;
+    }
+    let seconds = (filetime / INTERVALS_PER_SECOND).checked_sub(FILETIME_UNIX_EPOCH_SECONDS)?;
+    Utc.timestamp_opt(seconds as i64, 0).single()
+}
+
 fn normalize_path_for_git(path: &str) -> String {
     if let Some(stripped) = path.strip_prefix(r"\\?\UNC\") {
         return format!(r"\\{}", stripped);
@@ -563,9 +1665,90 @@ fn normalize_path_for_git(path: &str) -> String {
     path.to_string()
 }
 
+/// A throwaway grove layout for tests: an `origin` repo with one commit on
+/// `main` and a bare clone at `<dir>/project/project.git`.
+#[cfg(test)]
+pub struct TestRepo {
+    pub dir: PathBuf,
+    pub context: RepoContext,
+}
+
+#[cfg(test)]
+impl TestRepo {
+    /// Add a worktree under the project root, creating the branch if needed.
+    pub fn add_worktree(&self, branch: &str) -> PathBuf {
+        let path = self.context.project_root.join(branch);
+        let create = !branch_exists(&self.context, branch);
+        add_worktree(&self.context, &path.to_string_lossy(), branch, create, None).unwrap();
+        path
+    }
+}
+
+#[cfg(test)]
+impl Drop for TestRepo {
+    fn drop(&mut self) {
+        let _ = fs::remove_dir_all(&self.dir);
+    }
+}
+
+/// Run git in `dir` with a fixed identity, panicking on failure.
+#[cfg(test)]
+pub fn run_test_git(dir: &Path, args: &[&str]) -> String {
+    let output = Command::new("git")
+        .args([
+            "-c",
+            "user.name=Grove Test",
+            "-c",
+            "user.email=test@grove.dev",
+        ])
+        .args(args)
+        .current_dir(dir)
+        .output()
+        .unwrap();
+    assert!(
+        output.status.success(),
+        "git {:?} failed: {}",
+        args,
+        String::from_utf8_lossy(&output.stderr)
+    );
+    String::from_utf8_lossy(&output.stdout).to_string()
+}
+
+#[cfg(test)]
+pub fn create_test_repo(test_name: &str) -> TestRepo {
+    create_test_repo_with_branch(test_name, "main")
+}
+
+#[cfg(test)]
+pub fn create_test_repo_with_branch(test_name: &str, default_branch: &str) -> TestRepo {
+    let dir = crate::utils::make_temp_dir(test_name);
+    let origin = dir.join("origin");
+    fs::create_dir_all(&origin).unwrap();
+    run_test_git(&origin, &["init", "-q", "-b", default_branch]);
+    fs::write(origin.join("README.md"), "# Test\n").unwrap();
+    run_test_git(&origin, &["add", "README.md"]);
+    run_test_git(&origin, &["commit", "-q", "-m", "Initial commit"]);
+
+    let project_root = dir.join("project");
+    fs::create_dir_all(&project_root).unwrap();
+    let bare = project_root.join("project.git");
+    clone_bare_repository(&origin.to_string_lossy(), &bare.to_string_lossy()).unwrap();
+    run_test_git(&bare, &["config", "user.name", "Grove Test"]);
+    run_test_git(&bare, &["config", "user.email", "test@grove.dev"]);
+
+    TestRepo {
+        context: RepoContext {
+            repo_path: bare,
+            project_root,
+        },
+        dir,
+    }
+}
+
 #[cfg(test)]
 mod tests {
     use super::*;
+    use crate::utils::make_temp_dir;
     use chrono::DateTime;
 
     fn make_worktree(path: &str, branch: &str) -> Worktree {
@@ -576,8 +1759,10 @@ mod tests {
             created_at: DateTime::from_timestamp(0, 0).unwrap(),
             is_dirty: false,
             is_locked: false,
+            lock_reason: None,
             is_prunable: false,
             is_main: false,
+            is_dangling: false,
         }
     }
 
@@ -589,6 +1774,7 @@ mod tests {
         let worktrees = parse_worktree_lines(output);
         assert_eq!(worktrees.len(), 1);
         assert!(worktrees[0].is_locked);
+        assert!(worktrees[0].lock_reason.is_none());
     }
 
     #[test]
@@ -599,6 +1785,39 @@ mod tests {
         assert!(worktrees[0].is_prunable);
     }
 
+    #[test]
+    fn parse_prunable_and_locked_with_reasons() {
+        let output = "worktree /path/to/gone\nHEAD abc123\nbranch refs/heads/gone\nprunable gitdir file points to non-existent location\n\nworktree /path/to/kept\nHEAD def456\nbranch refs/heads/kept\nlocked on a USB drive\n";
+        let worktrees = parse_worktree_lines(output);
+        assert!(worktrees[0].is_prunable);
+        assert!(worktrees[1].is_locked);
+        assert_eq!(worktrees[1].lock_reason.as_deref(), Some("on a USB drive"));
+    }
+
+    #[test]
+    fn parse_worktree_status_headers_and_entries() {
+        let output = "# branch.oid abc123\n# branch.head feature-x\n# branch.upstream origin/feature-x\n# branch.ab +2 -1\n? notes.txt\n";
+        assert_eq!(
+            parse_worktree_status(output),
+            WorktreeStatus {
+                branch: Some("feature-x".to_string()),
+                head: "abc123".to_string(),
+                upstream: Some("origin/feature-x".to_string()),
+                ahead: 2,
+                behind: 1,
+                dirty: true,
+            }
+        );
+
+        let detached = parse_worktree_status("# branch.oid def456\n# branch.head (detached)\n");
+        assert_eq!(detached.branch, None);
+        assert_eq!(detached.head, "def456");
+        assert!(!detached.dirty);
+
+        let unborn = parse_worktree_status("# branch.oid (initial)\n# branch.head main\n");
+        assert_eq!(unborn.head, "");
+    }
+
     #[test]
     fn parse_detached_head() {
         let output = "worktree /path/to/worktree\nHEAD abc123def456\ndetached\n";
@@ -665,31 +1884,601 @@ mod tests {
     }
 
     #[test]
-    fn match_worktree_by_name_trims_trailing_slashes() {
+    fn resolve_worktree_trims_trailing_slashes() {
         let worktrees = vec![
             make_worktree("/repo/main", "main"),
             make_worktree("/repo/feature/my-branch", "feature/my-branch"),
         ];
 
-        let found = match_worktree_by_name(&worktrees, "feature/my-branch/");
-        assert_eq!(
-            found.map(|wt| wt.branch.as_str()),
-            Some("feature/my-branch")
-        );
+        let found = resolve_worktree(&worktrees, "feature/my-branch/");
+        assert_eq!(found.map(|wt| wt.branch.as_str()), Ok("feature/my-branch"));
     }
 
     #[test]
-    fn match_worktree_by_name_suffix_match_with_trailing_slash() {
+    fn resolve_worktree_suffix_match_with_trailing_slash() {
         let worktrees = vec![make_worktree(
             "/repo/feature/my-branch",
             "feature/my-branch",
         )];
 
-        let found = match_worktree_by_name(&worktrees, "my-branch/");
+        let found = resolve_worktree(&worktrees, "my-branch/");
+        assert_eq!(found.map(|wt| wt.branch.as_str()), Ok("feature/my-branch"));
+    }
+
+    #[test]
+    fn resolve_worktree_match_tiers() {
+        let worktrees = vec![
+            make_worktree("/repo/main", "main"),
+            // Directory name collides with another worktree's branch.
+            make_worktree("/repo/api", "feature/server"),
+            make_worktree("/repo/api-client", "api"),
+            make_worktree("/repo/docs-site", "docs/refresh"),
+        ];
+
+        let cases = [
+            ("/repo/api-client/", "/repo/api-client"),
+            ("api", "/repo/api"),
+            ("feature/server", "/repo/api"),
+            ("refresh", "/repo/docs-site"),
+            ("docs-s", "/repo/docs-site"),
+        ];
+
+        for (query, expected_path) in cases {
+            let found = resolve_worktree(&worktrees, query).unwrap();
+            assert_eq!(found.path, expected_path, "query: {}", query);
+        }
+    }
+
+    #[test]
+    fn resolve_worktree_reports_ambiguous_substring() {
+        let worktrees = vec![
+            make_worktree("/repo/feature-one", "feature/one"),
+            make_worktree("/repo/feature-two", "feature/two"),
+        ];
+
+        let err = resolve_worktree(&worktrees, "feature").unwrap_err();
+        assert_eq!(
+            err,
+            WorktreeLookupError::Ambiguous(
+                "feature".to_string(),
+                vec![
+                    "/repo/feature-one".to_string(),
+                    "/repo/feature-two".to_string()
+                ]
+            )
+        );
+    }
+
+    #[test]
+    fn resolve_worktree_reports_not_found() {
+        let worktrees = vec![make_worktree("/repo/main", "main")];
+
+        let err = resolve_worktree(&worktrees, "missing").unwrap_err();
+        assert_eq!(err, WorktreeLookupError::NotFound("missing".to_string()));
+        assert!(err.to_string().contains("grove list"));
+    }
+
+    #[test]
+    fn add_worktree_error_includes_git_stderr_verbatim() {
+        let repo = create_test_repo("add-fake-git");
+        let script = repo.dir.join("fake-git.sh");
+        fs::write(
+            &script,
+            "#!/bin/sh\necho \"fatal: could not create leading directories of 'C:/x': Filename too long\" >&2\necho \"hint: enable core.longpaths\" >&2\nexit 128\n",
+        )
+        .unwrap();
+
+        set_fake_git(Some(script));
+        let result = add_worktree(&repo.context, "/tmp/unused", "feature", true, None);
+        set_fake_git(None);
+
+        let err = result.unwrap_err();
+        assert!(err.contains(
+            "fatal: could not create leading directories of 'C:/x': Filename too long\nhint: enable core.longpaths"
+        ));
+    }
+
+    #[test]
+    fn case_insensitive_resolution_prefers_exact_then_ignores_case() {
+        let worktrees = vec![
+            make_worktree("/repo/Feature", "Feature"),
+            make_worktree("/repo/bugfix/Login", "bugfix/Login"),
+        ];
+
+        assert_eq!(
+            resolve_worktree_with(&worktrees, "feature", MatchBy::Any, true)
+                .unwrap()
+                .path,
+            "/repo/Feature"
+        );
+        assert_eq!(
+            resolve_worktree_with(&worktrees, "LOGIN", MatchBy::Any, true)
+                .unwrap()
+                .path,
+            "/repo/bugfix/Login"
+        );
+        assert!(matches!(
+            resolve_worktree_with(&worktrees, "feature", MatchBy::Any, false),
+            Err(WorktreeLookupError::NotFound(_))
+        ));
+
+        // Where both spellings exist, an exact match still wins.
+        let worktrees = vec![
+            make_worktree("/repo/Feature", "Feature"),
+            make_worktree("/repo/feature-2", "feature"),
+        ];
+        assert_eq!(
+            resolve_worktree_with(&worktrees, "feature", MatchBy::Any, true)
+                .unwrap()
+                .path,
+            "/repo/feature-2"
+        );
+    }
+
+    #[test]
+    fn match_by_separates_directory_names_from_branches() {
+        // The directory `review` holds `feature-x`, while `review` is checked out elsewhere.
+        let worktrees = vec![
+            make_worktree("/repo/review", "feature-x"),
+            make_worktree("/repo/wip", "review"),
+        ];
+
+        let found = |by| {
+            resolve_worktree_by(&worktrees, "review", by)
+                .unwrap()
+                .path
+                .clone()
+        };
+        assert_eq!(found(MatchBy::Any), "/repo/review");
+        assert_eq!(found(MatchBy::Path), "/repo/review");
+        assert_eq!(found(MatchBy::Branch), "/repo/wip");
+        assert_eq!(
+            resolve_worktree_by(&worktrees, "/repo/wip", MatchBy::Path)
+                .unwrap()
+                .branch,
+            "review"
+        );
+        assert!(matches!(
+            resolve_worktree_by(&worktrees, "/repo/wip", MatchBy::Branch),
+            Err(WorktreeLookupError::NotFound(_))
+        ));
+        assert!(matches!(
+            resolve_worktree_by(&worktrees, "feat", MatchBy::Branch),
+            Err(WorktreeLookupError::NotFound(_))
+        ));
+    }
+
+    #[test]
+    fn exact_matching_never_falls_back_to_substrings() {
+        let worktrees = vec![
+            make_worktree("/repo/feature-a", "feature-a"),
+            make_worktree("/repo/review", "bugfix-login"),
+        ];
+
+        assert!(resolve_worktree_by(&worktrees, "feat", MatchBy::Any).is_ok());
+        assert!(matches!(
+            resolve_worktree_with(&worktrees, "feat", MatchBy::Exact, true),
+            Err(WorktreeLookupError::NotFound(_))
+        ));
+        let found = |query| {
+            resolve_worktree_by(&worktrees, query, MatchBy::Exact)
+                .unwrap()
+                .path
+                .clone()
+        };
+        assert_eq!(found("/repo/review"), "/repo/review");
+        assert_eq!(found("review"), "/repo/review");
+        assert_eq!(found("bugfix-login"), "/repo/review");
+        assert_eq!(MatchBy::Any.exact(), MatchBy::Exact);
+        assert_eq!(MatchBy::Branch.exact(), MatchBy::Branch);
+    }
+
+    #[cfg(any(windows, target_os = "macos"))]
+    #[test]
+    fn resolve_worktree_ignores_case_on_this_platform() {
+        let worktrees = vec![make_worktree("/repo/Feature", "Feature")];
+        assert!(resolve_worktree(&worktrees, "feature").is_ok());
+    }
+
+    #[test]
+    fn gc_passes_aggressive_and_prune_through_to_git() {
+        let repo = create_test_repo("gc-fake-git");
+        let script = repo.dir.join("record-git.sh");
+        let record = repo.dir.join("git-args.txt");
+        fs::write(
+            &script,
+            format!("#!/bin/sh\necho \"$@\" >> '{}'\n", record.display()),
+        )
+        .unwrap();
+
+        set_fake_git(Some(script));
+        let aggressive = gc_repository(&repo.context, true, Some("2.weeks.ago"));
+        let plain = gc_repository(&repo.context, false, None);
+        set_fake_git(None);
+
+        aggressive.unwrap();
+        plain.unwrap();
+        assert_eq!(
+            fs::read_to_string(&record).unwrap(),
+            "gc --quiet --aggressive --prune=2.weeks.ago\ngc --quiet\n"
+        );
+    }
+
+    #[test]
+    fn gc_packs_loose_objects_in_bare_clone() {
+        let repo = create_test_repo("gc-real");
+        let worktree = repo.add_worktree("feature-a");
+        fs::write(worktree.join("a.txt"), "a").unwrap();
+        run_test_git(&worktree, &["add", "a.txt"]);
+        run_test_git(&worktree, &["commit", "-q", "-m", "a"]);
+
+        let before = object_counts(&repo.context).unwrap();
+        assert!(before.loose > 0);
+        gc_repository(&repo.context, false, Some("now")).unwrap();
+        let after = object_counts(&repo.context).unwrap();
+        assert_eq!(after.loose, 0);
+        assert!(after.packs >= 1);
+    }
+
+    #[test]
+    fn operation_in_progress_detects_rebase_in_worktree() {
+        let repo = create_test_repo("gc-in-progress");
+        repo.add_worktree("feature-a");
+        assert_eq!(operation_in_progress(&repo.context), None);
+
+        let admin_dir = repo_path(&repo.context).join("worktrees").join("feature-a");
+        fs::create_dir_all(admin_dir.join("rebase-merge")).unwrap();
+        assert_eq!(
+            operation_in_progress(&repo.context).as_deref(),
+            Some("a rebase in worktree 'feature-a'")
+        );
+    }
+
+    #[test]
+    fn parse_object_counts_reads_count_objects_output() {
+        let counts = parse_object_counts(
+            "count: 12\nsize: 48\nin-pack: 300\npacks: 2\nsize-pack: 1024\nprune-packable: 0\n",
+        );
+        assert_eq!(
+            counts,
+            ObjectCounts {
+                loose: 12,
+                loose_size_kb: 48,
+                packs: 2,
+                pack_size_kb: 1024,
+            }
+        );
+    }
+
+    #[test]
+    fn git_failure_without_stderr_reports_exit_status() {
+        let repo = create_test_repo("silent-fake-git");
+        let script = repo.dir.join("silent-git.sh");
+        fs::write(&script, "#!/bin/sh\nexit 3\n").unwrap();
+
+        set_fake_git(Some(script));
+        let result = git_raw(&repo.context, &["worktree", "add"]);
+        set_fake_git(None);
+
+        let err = result.unwrap_err();
+        assert!(err.contains("git worktree add exited with"));
+    }
+
+    #[test]
+    fn unborn_head_lists_with_empty_head_and_default_branch() {
+        let dir = make_temp_dir("unborn-head");
+        run_test_git(&dir, &["init", "-q", "-b", "trunk"]);
+        let context = open_repo(&dir, &dir);
+
+        let worktrees = list_worktrees(&context).unwrap();
+        assert_eq!(worktrees.len(), 1);
+        assert_eq!(worktrees[0].branch, "trunk");
+        assert_eq!(worktrees[0].head, "");
+        assert!(!worktrees[0].is_dangling);
+        assert_eq!(get_default_branch(&context).unwrap(), "trunk");
+
+        let _ = fs::remove_dir_all(dir);
+    }
+
+    #[test]
+    fn failed_add_leaves_no_branch_or_directory_behind() {
+        let repo = create_test_repo("add-rollback");
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["fetch", "-q", "origin"]);
+        let target = project_root(&repo.context).join("feature-partial");
+        // A worktree whose directory is gone is prunable, but a failed add
+        // isn't the place to decide that.
+        let missing = repo.add_worktree("feature-missing");
+        fs::remove_dir_all(&missing).unwrap();
+
+        // A held config lock makes git fail after it has already created the branch.
+        let lock = bare.join("config.lock");
+        fs::write(&lock, "").unwrap();
+        let result = add_worktree(
+            &repo.context,
+            &target.to_string_lossy(),
+            "feature-partial",
+            true,
+            Some("origin/main"),
+        );
+        fs::remove_file(&lock).unwrap();
+
+        assert!(result.is_err());
+        assert!(!branch_exists(&repo.context, "feature-partial"));
+        assert!(!target.exists());
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert!(worktrees.iter().all(|wt| wt.branch != "feature-partial"));
+        assert!(worktrees.iter().any(|wt| wt.branch == "feature-missing"));
+        assert!(bare.join("worktrees").join("feature-missing").is_dir());
+    }
+
+    #[test]
+    fn failed_add_keeps_preexisting_branch() {
+        let repo = create_test_repo("add-rollback-existing");
+        let bare = repo_path(&repo.context).to_path_buf();
+        run_test_git(&bare, &["branch", "feature-existing", "main"]);
+        let blocker = project_root(&repo.context).join("feature-existing");
+        fs::write(&blocker, "not a directory").unwrap();
+
+        let result = add_worktree(
+            &repo.context,
+            &blocker.to_string_lossy(),
+            "feature-existing",
+            false,
+            None,
+        );
+
+        assert!(result.is_err());
+        assert!(branch_exists(&repo.context, "feature-existing"));
+        assert!(blocker.is_file());
+    }
+
+    #[test]
+    fn parallel_removal_removes_all_and_reports_in_order() {
+        let repo = create_test_repo("remove-parallel");
+        for i in 0..5 {
+            let path = repo.add_worktree(&format!("feature-{}", i));
+            fs::write(path.join("scratch.txt"), "dirty").unwrap();
+        }
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        assert_eq!(worktrees.len(), 5);
+
+        let mut started = Vec::new();
+        let (removed, failed) =
+            remove_worktrees_parallel(&repo.context, &worktrees, true, 3, |wt| {
+                started.push(wt.branch.clone())
+            });
+
+        assert!(failed.is_empty(), "{:?}", failed);
+        let expected: Vec<String> = worktrees.iter().map(|wt| wt.path.clone()).collect();
+        assert_eq!(removed, expected);
+        assert_eq!(started.len(), 5);
+        assert!(worktrees.iter().all(|wt| !Path::new(&wt.path).exists()));
+        assert!(list_worktrees(&repo.context).unwrap().is_empty());
+    }
+
+    #[test]
+    fn submodule_changes_respect_ignore_submodules() {
+        let repo = create_test_repo("submodule-status");
+        let library = repo.dir.join("library");
+        fs::create_dir_all(&library).unwrap();
+        run_test_git(&library, &["init", "-q", "-b", "main"]);
+        fs::write(library.join("lib.txt"), "lib").unwrap();
+        run_test_git(&library, &["add", "lib.txt"]);
+        run_test_git(&library, &["commit", "-q", "-m", "lib"]);
+
+        let worktree = repo.add_worktree("feature-sub");
+        run_test_git(
+            &worktree,
+            &[
+                "-c",
+                "protocol.file.allow=always",
+                "submodule",
+                "add",
+                "-q",
+                &library.to_string_lossy(),
+                "vendor/library",
+            ],
+        );
+        run_test_git(&worktree, &["commit", "-q", "-m", "add submodule"]);
+        let submodule = worktree.join("vendor").join("library");
+        fs::write(submodule.join("lib.txt"), "x".repeat(100_000)).unwrap();
+
+        let dirty =
+            |dirty_check| list_worktrees_with(&repo.context, dirty_check).unwrap()[0].is_dirty;
+        assert!(dirty(DirtyCheck::Full));
+        assert!(!dirty(DirtyCheck::IgnoreSubmodules));
+
+        assert!(crate::utils::directory_size(&worktree) < 100_000);
+    }
+
+    #[test]
+    fn dirty_file_counts_separate_staged_unstaged_and_untracked() {
+        let repo = create_test_repo("dirty-counts");
+        let worktree = repo.add_worktree("feature-mixed");
+        for name in ["a.txt", "b.txt", "c.txt"] {
+            fs::write(worktree.join(name), "new").unwrap();
+        }
+        run_test_git(&worktree, &["add", "a.txt", "b.txt", "c.txt"]);
+        // c.txt is staged and then modified again, so it counts twice.
+        fs::write(worktree.join("c.txt"), "changed again").unwrap();
+        fs::write(worktree.join("README.md"), "edited").unwrap();
+        fs::write(worktree.join("untracked.txt"), "?").unwrap();
+
+        let counts = dirty_file_counts(&worktree.to_string_lossy(), false).unwrap();
+        assert_eq!(
+            counts,
+            DirtyFileCounts {
+                staged: 3,
+                unstaged: 2,
+                untracked: 1,
+            }
+        );
+    }
+
+    #[test]
+    fn create_branch_points_at_start_commit() {
+        let repo = create_test_repo("create-branch");
+        let bare = repo_path(&repo.context).to_path_buf();
+        let main_commit = run_test_git(&bare, &["rev-parse", "main"]);
+
+        create_branch(&repo.context, "feature/start", main_commit.trim()).unwrap();
+        assert_eq!(
+            run_test_git(&bare, &["rev-parse", "feature/start"]),
+            main_commit
+        );
+        assert!(create_branch(&repo.context, "feature/start", "main")
+            .unwrap_err()
+            .contains("already exists"));
+    }
+
+    #[test]
+    fn delete_branch_refuses_unmerged_unless_forced() {
+        let repo = create_test_repo("delete-branch");
+        let bare = repo_path(&repo.context).to_path_buf();
+        let unmerged = run_test_git(
+            &bare,
+            &["commit-tree", "main^{tree}", "-p", "main", "-m", "unmerged"],
+        );
+        create_branch(&repo.context, "unmerged", unmerged.trim()).unwrap();
+        create_branch(&repo.context, "merged", "main").unwrap();
+
+        delete_branch(&repo.context, "merged", false).unwrap();
+        assert!(!branch_exists(&repo.context, "merged"));
+
+        let err = delete_branch(&repo.context, "unmerged", false).unwrap_err();
+        assert!(err.contains("not fully merged"), "{}", err);
+        assert!(branch_exists(&repo.context, "unmerged"));
+
+        delete_branch(&repo.context, "unmerged", true).unwrap();
+        assert!(!branch_exists(&repo.context, "unmerged"));
+    }
+
+    #[test]
+    fn filetime_conversion_uses_1601_epoch() {
+        assert_eq!(filetime_to_datetime(0), None);
+        // 1601-01-01 + 11644473600s is exactly the Unix epoch.
+        assert_eq!(
+            filetime_to_datetime(FILETIME_UNIX_EPOCH_SECONDS * 10_000_000),
+            Utc.timestamp_opt(0, 0).single()
+        );
+        // 2024-01-01T00:00:00Z
+        assert_eq!(
+            filetime_to_datetime(133_485_408_000_000_000),
+            Utc.timestamp_opt(1_704_067_200, 0).single()
+        );
+    }
+
+    #[cfg(windows)]
+    #[test]
+    fn created_at_of_fresh_file_is_now_on_windows() {
+        use std::os::windows::fs::MetadataExt;
+        let dir = make_temp_dir("created-at-windows");
+        let before = Utc::now() - chrono::Duration::seconds(5);
+        let file = dir.join("fresh.txt");
+        fs::write(&file, "x").unwrap();
+        let after = Utc::now() + chrono::Duration::seconds(5);
+
+        let meta = fs::metadata(&file).unwrap();
+        let raw = filetime_to_datetime(meta.creation_time()).unwrap();
+        assert!(raw >= before && raw <= after, "{}", raw);
+        let created = metadata_created_at(&meta).unwrap();
+        assert!(created >= before && created <= after, "{}", created);
+        let _ = fs::remove_dir_all(&dir);
+    }
+
+    #[test]
+    fn resolve_revision_rejects_unknown_refs() {
+        let repo = create_test_repo("resolve-revision");
+        let bare = repo_path(&repo.context).to_path_buf();
+        assert_eq!(
+            resolve_revision(&repo.context, "main").unwrap(),
+            run_test_git(&bare, &["rev-parse", "main"]).trim()
+        );
         assert_eq!(
-            found.map(|wt| wt.branch.as_str()),
-            Some("feature/my-branch")
+            resolve_revision(&repo.context, "no-such-ref").unwrap_err(),
+            "Unknown revision 'no-such-ref'"
+        );
+    }
+
+    #[test]
+    fn is_stash_entry_accepts_only_stash_list_entries() {
+        let repo = create_test_repo("stash-entry");
+        let source = repo.add_worktree("source");
+        assert!(!is_stash_entry(&repo.context, "stash@{0}"));
+
+        fs::write(source.join("README.md"), "# In progress\n").unwrap();
+        run_test_git(&source, &["stash", "-q"]);
+
+        assert!(is_stash_entry(&repo.context, "stash@{0}"));
+        assert!(!is_stash_entry(&repo.context, "stash@{1}"));
+        assert!(!is_stash_entry(&repo.context, "main"));
+        assert!(!is_stash_entry(&repo.context, "source"));
+    }
+
+    #[test]
+    fn registration_fallback_ignores_later_commits() {
+        let repo = create_test_repo("registered-at-fallback");
+        let worktree = repo.add_worktree("feature-a");
+        let admin_dir = repo_path(&repo.context).join("worktrees").join("feature-a");
+        let written = gitdir_written_at(&admin_dir).unwrap();
+
+        std::thread::sleep(std::time::Duration::from_millis(20));
+        fs::write(worktree.join("work.txt"), "work\n").unwrap();
+        run_test_git(&worktree, &["add", "work.txt"]);
+        run_test_git(&worktree, &["commit", "-q", "-m", "work"]);
+
+        assert_eq!(gitdir_written_at(&admin_dir).unwrap(), written);
+        assert!(registered_at(&worktree).is_some());
+    }
+
+    #[test]
+    fn worktree_with_deleted_branch_is_dangling() {
+        let repo = create_test_repo("dangling-branch");
+        repo.add_worktree("feature-kept");
+        repo.add_worktree("feature-gone");
+        run_test_git(
+            repo_path(&repo.context),
+            &["update-ref", "-d", "refs/heads/feature-gone"],
+        );
+
+        let worktrees = list_worktrees(&repo.context).unwrap();
+        let gone = worktrees
+            .iter()
+            .find(|wt| wt.branch == "feature-gone")
+            .unwrap();
+        assert!(gone.is_dangling);
+        let kept = worktrees
+            .iter()
+            .find(|wt| wt.branch == "feature-kept")
+            .unwrap();
+        assert!(!kept.is_dangling);
+    }
+
+    #[test]
+    fn detached_head_is_never_dangling() {
+        assert!(!is_dangling_branch(DETACHED_HEAD, ""));
+        assert!(is_dangling_branch("feature", &"0".repeat(40)));
+        assert!(!is_dangling_branch("feature", "abc123"));
+    }
+
+    #[test]
+    fn get_default_branch_reads_bare_head() {
+        let repo = create_test_repo_with_branch("default-branch-master", "master");
+        // A stray `main` branch must not win over the repo's actual default.
+        run_test_git(&repo.context.repo_path, &["branch", "main", "master"]);
+        assert_eq!(get_default_branch(&repo.context).unwrap(), "master");
+    }
+
+    #[test]
+    fn get_default_branch_prefers_origin_head() {
+        let repo = create_test_repo_with_branch("default-branch-origin-head", "trunk");
+        run_test_git(&repo.context.repo_path, &["fetch", "-q", "origin"]);
+        run_test_git(
+            &repo.context.repo_path,
+            &["remote", "set-head", "origin", "trunk"],
         );
+        assert_eq!(get_default_branch(&repo.context).unwrap(), "trunk");
     }
 
     #[test]
@@ -699,6 +2488,8 @@ mod tests {
             "pr-9148",
             true,
             Some("origin/some-remote-branch"),
+            None,
+            None,
         );
 
         assert_eq!(
@@ -717,7 +2508,7 @@ mod tests {
 
     #[test]
     fn build_add_worktree_args_for_new_branch_without_track() {
-        let args = build_add_worktree_args("/tmp/repo/feature", "feature", true, None);
+        let args = build_add_worktree_args("/tmp/repo/feature", "feature", true, None, None, None);
 
         assert_eq!(
             args,
@@ -725,6 +2516,31 @@ mod tests {
         );
     }
 
+    #[test]
+    fn build_add_worktree_args_for_new_branch_from_start_point() {
+        let args = build_add_worktree_args(
+            "/tmp/repo/feature",
+            "feature",
+            true,
+            None,
+            Some("origin/main"),
+            None,
+        );
+
+        assert_eq!(
+            args,
+            vec![
+                "worktree",
+                "add",
+                "-b",
+                "feature",
+                "--no-track",
+                "/tmp/repo/feature",
+                "origin/main",
+            ]
+        );
+    }
+
     #[test]
     fn build_add_worktree_args_for_existing_branch_ignores_track() {
         let args = build_add_worktree_args(
@@ -732,6 +2548,8 @@ mod tests {
             "existing",
             false,
             Some("origin/existing"),
+            None,
+            None,
         );
 
         assert_eq!(
@@ -740,6 +2558,34 @@ mod tests {
         );
     }
 
+    #[test]
+    fn build_add_worktree_args_locks_in_the_same_call() {
+        let args = build_add_worktree_args(
+            "/tmp/repo/feature",
+            "feature",
+            true,
+            None,
+            None,
+            Some(WorktreeLock {
+                reason: Some("CI runner"),
+            }),
+        );
+
+        assert_eq!(
+            args,
+            vec![
+                "worktree",
+                "add",
+                "--lock",
+                "--reason",
+                "CI runner",
+                "-b",
+                "feature",
+                "/tmp/repo/feature",
+            ]
+        );
+    }
+
     #[test]
     fn parse_remote_tracking_reference_short_form() {
         assert_eq!(
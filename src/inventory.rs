@@ -0,0 +1,128 @@
+use chrono::{DateTime, Utc};
+
+/// A portable description of a grove project: where it was cloned from, its
+/// default branch, and which branches are checked out in which directories.
+///
+/// The file format is line based so it diffs cleanly and tolerates comments:
+///
+/// ```text
+/// # grove worktree inventory
+/// url https://github.com/owner/repo.git
+/// base main
+/// # created 2026-01-02T03:04:05Z
+/// worktree feature-a feature-a
+/// ```
+///
+/// Each `worktree` line is `worktree <branch> <directory>`, where the directory
+/// is relative to the project root. Branch names can't contain spaces, so the
+/// rest of the line after the branch is the directory.
+#[derive(Debug, Clone, PartialEq, Eq, Default)]
+pub struct Inventory {
+    pub url: Option<String>,
+    pub base: Option<String>,
+    pub worktrees: Vec<InventoryEntry>,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct InventoryEntry {
+    pub branch: String,
+    pub directory: String,
+    /// Only written as a comment; not read back.
+    pub created_at: Option<DateTime<Utc>>,
+}
+
+pub fn render_inventory(inventory: &Inventory) -> String {
+    let mut output = String::from("# grove worktree inventory\n");
+    if let Some(url) = &inventory.url {
+        output.push_str(&format!("url {}\n", url));
+    }
+    if let Some(base) = &inventory.base {
+        output.push_str(&format!("base {}\n", base));
+    }
+    for entry in &inventory.worktrees {
+        if let Some(created_at) = entry.created_at {
+            output.push_str(&format!(
+                "# created {}\n",
+                created_at.to_rfc3339_opts(chrono::SecondsFormat::Secs, true)
+            ));
+        }
+        output.push_str(&format!("worktree {} {}\n", entry.branch, entry.directory));
+    }
+    output
+}
+
+pub fn parse_inventory(content: &str) -> Result<Inventory, String> {
+    let mut inventory = Inventory::default();
+
+    for (index, raw_line) in content.lines().enumerate() {
+        let line = raw_line.trim();
+        if line.is_empty() || line.starts_with('#') {
+            continue;
+        }
+
+        let line_error = |message: &str| format!("Line {}: {}", index + 1, message);
+        let (keyword, rest) = line
+            .split_once(char::is_whitespace)
+            .map(|(k, r)| (k, r.trim()))
+            .unwrap_or((line, ""));
+
+        match keyword {
+            "url" if !rest.is_empty() => inventory.url = Some(rest.to_string()),
+            "base" if !rest.is_empty() => inventory.base = Some(rest.to_string()),
+            "worktree" => {
+                let (branch, directory) = rest
+                    .split_once(char::is_whitespace)
+                    .map(|(b, d)| (b, d.trim()))
+                    .filter(|(b, d)| !b.is_empty() && !d.is_empty())
+                    .ok_or_else(|| line_error("expected 'worktree <branch> <directory>'"))?;
+                inventory.worktrees.push(InventoryEntry {
+                    branch: branch.to_string(),
+                    directory: directory.to_string(),
+                    created_at: None,
+                });
+            }
+            "url" | "base" => return Err(line_error(&format!("'{}' needs a value", keyword))),
+            other => return Err(line_error(&format!("unknown entry '{}'", other))),
+        }
+    }
+
+    Ok(inventory)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use chrono::TimeZone;
+
+    #[test]
+    fn render_then_parse_round_trips() {
+        let inventory = Inventory {
+            url: Some("https://example.com/repo.git".to_string()),
+            base: Some("main".to_string()),
+            worktrees: vec![InventoryEntry {
+                branch: "feature/a".to_string(),
+                directory: "work/feature a".to_string(),
+                created_at: Some(Utc.with_ymd_and_hms(2026, 1, 2, 3, 4, 5).unwrap()),
+            }],
+        };
+
+        let rendered = render_inventory(&inventory);
+        assert!(rendered.contains("# created 2026-01-02T03:04:05Z\n"));
+
+        let parsed = parse_inventory(&rendered).unwrap();
+        assert_eq!(parsed.url, inventory.url);
+        assert_eq!(parsed.base, inventory.base);
+        assert_eq!(parsed.worktrees[0].branch, "feature/a");
+        assert_eq!(parsed.worktrees[0].directory, "work/feature a");
+        assert_eq!(parsed.worktrees[0].created_at, None);
+    }
+
+    #[test]
+    fn parse_reports_line_numbers() {
+        let err = parse_inventory("# header\nworktree only-branch\n").unwrap_err();
+        assert!(err.starts_with("Line 2:"));
+
+        let err = parse_inventory("bogus value\n").unwrap_err();
+        assert!(err.contains("unknown entry 'bogus'"));
+    }
+}
@@ -1,15 +1,23 @@
-use clap::{Parser, Subcommand};
+use clap::{ArgGroup, Parser, Subcommand};
 use colored::Colorize;
 use regex::Regex;
-use std::path::Path;
+use std::path::{Path, PathBuf};
 
 mod commands;
 mod git;
+mod inventory;
 mod models;
+mod progress;
+mod prompt;
+mod timing;
 mod utils;
 
-use crate::git::normalize_tracking_reference_input;
-use crate::utils::{is_valid_git_url, parse_duration, trim_trailing_branch_slashes};
+use crate::git::{normalize_tracking_reference_input, MatchBy};
+use crate::models::{AddOptions, PruneOptions, TableSpacing, WorktreeListOptions};
+use crate::utils::{
+    absolute_path, is_valid_git_url, parse_duration, read_config, set_config_path,
+    trim_trailing_branch_slashes,
+};
 
 const VERSION: &str = env!("CARGO_PKG_VERSION");
 
@@ -50,6 +58,35 @@ fn validate_pr_number(value: &str) -> Result<u64, String> {
     Ok(parsed)
 }
 
+fn validate_issue_number(value: &str) -> Result<u64, String> {
+    match value.trim_start_matches('#').parse::<u64>() {
+        Ok(parsed) if parsed > 0 => Ok(parsed),
+        _ => Err(format!(
+            "Invalid issue number: {} (must be a positive integer)",
+            value
+        )),
+    }
+}
+
+/// What `--branch` or `--path` limits a worktree name to.
+fn match_by(branch: bool, path: bool) -> MatchBy {
+    match (branch, path) {
+        (true, _) => MatchBy::Branch,
+        (_, true) => MatchBy::Path,
+        _ => MatchBy::Any,
+    }
+}
+
+fn validate_parallel_jobs(value: &str) -> Result<usize, String> {
+    match value.parse::<usize>() {
+        Ok(parsed) if parsed > 0 => Ok(parsed),
+        _ => Err(format!(
+            "Invalid job count: {} (must be a positive integer)",
+            value
+        )),
+    }
+}
+
 fn validate_version(value: &str) -> Result<String, String> {
     let re = Regex::new(r"^v?\d+\.\d+\.\d+(-[\w.]+)?$").unwrap();
     if re.is_match(value) {
@@ -70,6 +107,12 @@ fn validate_tracking_reference(value: &str) -> Result<String, String> {
 #[derive(Parser)]
 #[command(name = "grove", about = "Grove is a Git worktree management tool", version = VERSION)]
 struct Cli {
+    /// Use this config file instead of ~/.config/grove/config.json
+    #[arg(long = "config", global = true, value_name = "PATH")]
+    config_path: Option<PathBuf>,
+    /// Print how long each phase of the command took to stderr
+    #[arg(long, global = true)]
+    timing: bool,
     #[command(subcommand)]
     command: Option<Commands>,
 }
@@ -84,23 +127,131 @@ enum Commands {
         /// Set up tracking for the specified remote branch
         #[arg(short = 't', long = "track", value_parser = validate_tracking_reference)]
         track: Option<String>,
+        /// Create the worktree at this path instead of under the project root
+        #[arg(long = "at", value_name = "PATH")]
+        at: Option<String>,
+        /// Replace a leftover directory at the target path (never a registered worktree)
+        #[arg(short = 'f', long)]
+        force: bool,
+        /// Name the worktree after an issue number (uses issueBranchTemplate from .groverc)
+        #[arg(long, value_name = "NUMBER", conflicts_with = "name", value_parser = validate_issue_number)]
+        issue: Option<u64>,
+        /// Ask origin for the branch when it doesn't exist locally, and track it if found
+        #[arg(long, conflicts_with = "track")]
+        fetch: bool,
+        /// Copy the files matching copyFiles in .groverc from this worktree
+        #[arg(long = "copy-from", value_name = "WORKTREE")]
+        copy_from: Option<String>,
+        /// Copy this directory's contents into the new worktree (overrides worktreeTemplateDir)
+        #[arg(long, value_name = "DIR")]
+        template: Option<String>,
+        /// Don't run the bootstrap commands from .groverc
+        #[arg(long = "no-hooks")]
+        no_hooks: bool,
+        /// Print the GitHub or GitLab URL for opening a pull request from the new branch
+        #[arg(long = "open-pr-url")]
+        open_pr_url: bool,
+        /// Print only the new worktree's path on stdout, for use in scripts
+        #[arg(short, long, visible_alias = "print-path")]
+        quiet: bool,
+        /// Start a new branch at REF instead of the default branch
+        #[arg(long, value_name = "REF", conflicts_with = "track")]
+        from: Option<String>,
+        /// Show the branch and path that would be used without creating anything
+        #[arg(long = "dry-run")]
+        dry_run: bool,
+        /// Apply a stash in the new worktree (default stash@{0}; pick another with --from-stash=STASH)
+        #[arg(
+            long = "from-stash",
+            value_name = "STASH",
+            num_args = 0..=1,
+            require_equals = true,
+            default_missing_value = "stash@{0}"
+        )]
+        from_stash: Option<String>,
+        /// Drop the stash once it applies cleanly
+        #[arg(long, requires = "from_stash")]
+        pop: bool,
+        /// Push the branch to origin and set it as the upstream
+        #[arg(long)]
+        push: bool,
+        /// Lock the worktree as it is created so prune can't remove it
+        #[arg(long)]
+        lock: bool,
+        /// Reason to record with --lock
+        #[arg(long, value_name = "REASON", requires = "lock")]
+        reason: Option<String>,
+    },
+    /// Manage grove configuration
+    Config {
+        #[command(subcommand)]
+        command: ConfigCommands,
+    },
+    /// Check worktrees for orphaned metadata, broken links, and other problems
+    Doctor {
+        /// Prune orphaned metadata and repair broken .git links
+        #[arg(long)]
+        fix: bool,
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
+    },
+    /// Print the worktree inventory for recreating it with 'grove sync --inventory'
+    Export,
+    /// Run git gc on the bare clone to pack loose objects
+    Gc {
+        /// Optimize the repository more thoroughly, at the cost of time
+        #[arg(long)]
+        aggressive: bool,
+        /// Prune loose objects older than DATE (passed to git gc --prune)
+        #[arg(long, value_name = "DATE")]
+        prune: Option<String>,
     },
     /// Navigate to a worktree by branch name
     Go {
-        /// Branch name or worktree name to navigate to (optional)
+        /// Branch name or worktree name to navigate to, or '-' for the previous worktree (optional)
         name: Option<String>,
         /// Output path only (used by shell integration)
         #[arg(short = 'p', long = "path-only")]
         path_only: bool,
+        /// Create the worktree (and branch, if needed) when none matches NAME
+        #[arg(short = 'c', long, requires = "name")]
+        create: bool,
+        /// Match NAME against branch names only
+        #[arg(long, conflicts_with = "path")]
+        branch: bool,
+        /// Match NAME against worktree paths and directory names only
+        #[arg(long, conflicts_with = "create")]
+        path: bool,
+    },
+    /// Run configured hooks by hand
+    Hooks {
+        #[command(subcommand)]
+        command: HooksCommands,
+    },
+    /// Show how grove sees the current repository
+    Info {
+        /// Output in JSON format
+        #[arg(long)]
+        json: bool,
     },
     /// Initialize a new worktree setup
     Init {
         /// Git repository URL to clone
         #[arg(value_parser = validate_git_url)]
         git_url: String,
+        /// Also create a worktree for the default branch
+        #[arg(long = "checkout-default")]
+        checkout_default: bool,
+        /// Only create the bare clone (the default)
+        #[arg(long = "no-checkout-default", conflicts_with = "checkout_default")]
+        no_checkout_default: bool,
     },
     /// List all worktrees
-    #[command(alias = "ls")]
+    #[command(
+        alias = "ls",
+        group(ArgGroup::new("behind_group").args(["behind", "behind_only"]).multiple(true))
+    )]
     List {
         /// Show detailed information
         #[arg(long)]
@@ -111,9 +262,116 @@ enum Commands {
         /// Show only locked worktrees
         #[arg(long)]
         locked: bool,
+        /// Show only locked worktrees whose lock reason contains PATTERN (case-insensitive)
+        #[arg(long = "locked-reason", value_name = "PATTERN")]
+        locked_reason: Option<String>,
+        /// Show only worktrees whose branch no longer exists
+        #[arg(long)]
+        dangling: bool,
+        /// Include worktrees hidden by .groveignore
+        #[arg(long)]
+        all: bool,
+        /// Show only the primary worktree: the one on the default branch, else main or master
+        #[arg(long = "main-only", conflicts_with = "jsonl")]
+        main_only: bool,
+        /// Don't count changes inside submodules as dirty
+        #[arg(long = "ignore-submodules")]
+        ignore_submodules: bool,
+        /// Skip the per-worktree git status; dirty status shows as unknown
+        #[arg(
+            long = "no-dirty-check",
+            conflicts_with_all = ["dirty", "dirty_files", "ignore_submodules", "dirty_check"]
+        )]
+        no_dirty_check: bool,
+        /// Check dirty status even when listDirtyCheck is false in the config
+        #[arg(long = "dirty-check")]
+        dirty_check: bool,
+        /// Don't print the project root, bare clone, and default branch above the list
+        #[arg(long = "no-header", conflicts_with_all = ["json", "jsonl", "path_only", "count"])]
+        no_header: bool,
+        /// Show staged, unstaged, and untracked file counts for dirty worktrees
+        #[arg(long = "dirty-files", conflicts_with_all = ["json", "jsonl", "fields", "path_only"])]
+        dirty_files: bool,
+        /// Show how many commits each branch has that aren't in REF (e.g. origin/release)
+        #[arg(long, value_name = "REF", conflicts_with_all = ["json", "jsonl", "fields", "path_only"])]
+        since: Option<String>,
+        /// Show only worktrees whose branch has commits not yet pushed to its upstream
+        #[arg(long = "remote-ahead")]
+        remote_ahead: bool,
+        /// Show only worktrees outside the project root (e.g. created with 'add --at')
+        #[arg(long)]
+        external: bool,
+        /// Show only worktrees created within DURATION (e.g. 7d, 2w, 12h)
+        #[arg(long = "newer-than", value_name = "DURATION", value_parser = validate_duration)]
+        newer_than: Option<String>,
+        /// Sort by most recent activity (commit, file changes, or creation) and show it
+        #[arg(long, conflicts_with_all = ["jsonl", "fields", "count"])]
+        activity: bool,
+        /// Sort by when each worktree was added (registered with git), oldest first
+        #[arg(long = "order-added", conflicts_with = "activity")]
+        order_added: bool,
+        /// Show how many commits each branch is behind the base branch
+        #[arg(long, conflicts_with_all = ["json", "jsonl", "fields", "path_only"])]
+        behind: bool,
+        /// Like --behind, but hide worktrees that are already up to date
+        #[arg(long = "behind-only", conflicts_with_all = ["json", "jsonl", "fields", "path_only"])]
+        behind_only: bool,
+        /// Base branch for --behind (defaults to the repository's default branch)
+        #[arg(long, value_name = "REF", requires = "behind_group")]
+        base: Option<String>,
+        /// Show only worktrees whose tip commit author name or email contains PATTERN
+        #[arg(long, value_name = "PATTERN")]
+        author: Option<String>,
+        /// Show only worktrees whose tip commit committer name or email contains PATTERN
+        #[arg(long, value_name = "PATTERN")]
+        committer: Option<String>,
+        /// Comma-separated columns to show, in order (path,branch,head,created,status,upstream,size,last-commit)
+        #[arg(long, value_name = "FIELDS")]
+        fields: Option<String>,
+        /// Show worktree paths relative to DIR (e.g. the project root)
+        #[arg(long = "relative-to", value_name = "DIR", conflicts_with_all = ["json", "jsonl"])]
+        relative_to: Option<String>,
+        /// Print only worktree paths, one per line
+        #[arg(long = "path-only", conflicts_with_all = ["json", "jsonl", "fields", "details"])]
+        path_only: bool,
+        /// Print only the number of matching worktrees
+        #[arg(long, conflicts_with_all = [
+            "json", "jsonl", "fields", "path_only", "details", "dirty_files", "since",
+            "behind", "behind_only",
+        ])]
+        count: bool,
+        /// How to show creation times: relative ("3 days ago") or absolute (exact UTC timestamps)
+        #[arg(long, value_name = "FORMAT", value_parser = ["relative", "absolute"], default_value = "relative")]
+        created: String,
+        /// Show at most N matching worktrees
+        #[arg(long, value_name = "N", conflicts_with_all = ["jsonl", "count"])]
+        limit: Option<usize>,
+        /// Skip the first N matching worktrees
+        #[arg(long, value_name = "N", conflicts_with_all = ["jsonl", "count"])]
+        offset: Option<usize>,
+        /// Pad columns only as much as their contents need
+        #[arg(long, conflicts_with_all = ["wide", "json", "jsonl", "path_only", "count"])]
+        compact: bool,
+        /// Leave extra space between columns
+        #[arg(long, conflicts_with_all = ["json", "jsonl", "path_only", "count"])]
+        wide: bool,
         /// Output in JSON format
         #[arg(long)]
         json: bool,
+        /// Include each branch's upstream and ahead/behind counts in --json output
+        #[arg(long = "ahead-behind", requires = "json", conflicts_with = "fields")]
+        ahead_behind: bool,
+        /// Stream one JSON object per line as each worktree is inspected
+        #[arg(long, conflicts_with = "json")]
+        jsonl: bool,
+    },
+    /// Check out a different existing branch in a worktree
+    MvBranch {
+        /// Branch name or path of the worktree to change
+        name: String,
+        /// Existing branch to check out in the worktree
+        #[arg(value_parser = validate_branch_name)]
+        branch: String,
     },
     /// Checkout a GitHub pull request into a new worktree
     Pr {
@@ -126,15 +384,81 @@ enum Commands {
         /// Show what would be removed without actually removing
         #[arg(long)]
         dry_run: bool,
-        /// Skip confirmation and remove worktrees even with uncommitted changes
+        /// List candidates without removing anything and exit 1 if there are any (for CI checks)
+        #[arg(
+            long = "report-only",
+            conflicts_with_all = ["dry_run", "force", "force_dirty", "remove_branch", "parallel", "log"]
+        )]
+        report_only: bool,
+        /// Skip confirmation and override every guard: uncommitted changes, unpushed commits,
+        /// and unmerged branches with --remove-branch
         #[arg(short = 'f', long)]
         force: bool,
-        /// Base branch to check for merged branches
-        #[arg(long)]
-        base: Option<String>,
+        /// Skip confirmation and remove worktrees with uncommitted changes, but still skip
+        /// unpushed commits
+        #[arg(long = "force-dirty", conflicts_with = "force")]
+        force_dirty: bool,
+        /// Base branch to check for merged branches (defaults to the repository's default branch).
+        /// Repeat or comma-separate to prune branches merged into any of them
+        #[arg(long, value_delimiter = ',')]
+        base: Vec<String>,
         /// Prune worktrees older than specified duration (e.g., 30d, 2w, 6M, 1y)
         #[arg(long = "older-than", value_parser = validate_duration)]
         older_than: Option<String>,
+        /// Only prune merged worktrees whose branch has had no commits for this long (e.g., 30d, 2w)
+        #[arg(long = "since-last-commit", value_parser = validate_duration, conflicts_with = "older_than")]
+        since_last_commit: Option<String>,
+        /// Never prune worktrees created within this duration (e.g., 1h, 2d), even if merged
+        #[arg(long = "min-age", value_parser = validate_duration)]
+        min_age: Option<String>,
+        /// Also prune detached HEAD worktrees by age (requires --older-than)
+        #[arg(long = "include-detached", requires = "older_than")]
+        include_detached: bool,
+        /// Also delete the local branch of each removed worktree (age-pruned branches need --force)
+        #[arg(long = "remove-branch")]
+        remove_branch: bool,
+        /// Remove worktrees concurrently with up to JOBS workers (default 4)
+        #[arg(
+            long,
+            value_name = "JOBS",
+            num_args = 0..=1,
+            default_missing_value = "4",
+            value_parser = validate_parallel_jobs
+        )]
+        parallel: Option<usize>,
+        /// Append a JSON line for each removed worktree to this file
+        #[arg(long, value_name = "FILE")]
+        log: Option<String>,
+        /// Show each worktree's disk usage and the total space that would be freed
+        #[arg(long)]
+        size: bool,
+        /// How to show creation times: relative (dates) or absolute (exact UTC timestamps)
+        #[arg(long, value_name = "FORMAT", value_parser = ["relative", "absolute"], default_value = "relative")]
+        created: String,
+    },
+    /// Rebase worktree branches onto an updated base branch
+    Rebase {
+        /// Branch name or path of the worktree to rebase
+        #[arg(required_unless_present = "all", conflicts_with = "all")]
+        name: Option<String>,
+        /// Rebase every clean feature worktree
+        #[arg(long)]
+        all: bool,
+        /// Branch to rebase onto (defaults to the repository's default branch)
+        #[arg(long)]
+        onto: Option<String>,
+    },
+    /// Move all worktrees under the project root into a subdirectory
+    RelocateRoot {
+        /// Subdirectory of the project root to move worktrees into
+        #[arg(default_value = "worktrees")]
+        directory: String,
+        /// Also move worktrees with uncommitted changes
+        #[arg(short = 'f', long)]
+        force: bool,
+        /// Rewrite old worktree paths in editor workspace files (ideFiles in .groverc)
+        #[arg(long = "update-ide")]
+        update_ide: bool,
     },
     /// Remove a worktree
     #[command(alias = "rm")]
@@ -147,6 +471,32 @@ enum Commands {
         /// Skip confirmation prompt
         #[arg(short = 'y', long)]
         yes: bool,
+        /// Keep each removed worktree's branch (the default)
+        #[arg(long = "keep-branch", conflicts_with = "delete_branch")]
+        keep_branch: bool,
+        /// Also delete each removed worktree's branch; unmerged branches need --force
+        #[arg(long = "delete-branch")]
+        delete_branch: bool,
+        /// Match NAMES against branch names only
+        #[arg(long, conflicts_with = "path")]
+        branch: bool,
+        /// Match NAMES against worktree paths and directory names only
+        #[arg(long)]
+        path: bool,
+    },
+    /// Hard-reset a worktree to its upstream, discarding local changes
+    Reset {
+        /// Branch name or path of the worktree to reset
+        name: String,
+        /// Reset to REF instead of the branch's upstream
+        #[arg(long, value_name = "REF")]
+        to: Option<String>,
+        /// Actually reset; without it, only report what would be discarded
+        #[arg(short = 'f', long)]
+        force: bool,
+        /// Allow resetting the main worktree
+        #[arg(long = "allow-main")]
+        allow_main: bool,
     },
     /// Update grove to a specific version or PR
     SelfUpdate {
@@ -163,11 +513,66 @@ enum Commands {
         #[arg(value_parser = ["bash", "zsh", "fish", "pwsh", "powershell"])]
         shell: String,
     },
+    /// Show the current worktree's branch, upstream divergence, and changes
+    Status {
+        /// Print a single line like 'feature-x *+2-1' for prompts and scripts
+        #[arg(long, conflicts_with = "json")]
+        short: bool,
+        /// Output as JSON (branch, upstream, ahead, behind, and file counts)
+        #[arg(long)]
+        json: bool,
+        /// With --json, output an array with the status of every worktree
+        #[arg(long, requires = "json")]
+        all: bool,
+    },
     /// Sync the bare clone with the latest changes from origin
     Sync {
-        /// Branch to sync (defaults to main or master)
-        #[arg(short = 'b', long = "branch")]
+        /// Branch to sync (defaults to the repository's default branch)
+        #[arg(short = 'b', long = "branch", conflicts_with = "inventory")]
         branch: Option<String>,
+        /// Recreate the worktrees listed in a file written by 'grove export'
+        #[arg(long = "inventory", value_name = "FILE")]
+        inventory: Option<String>,
+        /// Recreate up to JOBS worktrees from the inventory at a time
+        #[arg(
+            short = 'j',
+            long,
+            value_name = "JOBS",
+            default_value = "1",
+            requires = "inventory",
+            value_parser = validate_parallel_jobs
+        )]
+        jobs: usize,
+    },
+    /// Delete the bare clone and every worktree once you're done with a project
+    Uninit {
+        /// Delete worktrees even if they have uncommitted or unpushed changes
+        #[arg(short = 'f', long)]
+        force: bool,
+        /// Skip confirmation prompt
+        #[arg(short = 'y', long)]
+        yes: bool,
+    },
+    /// Print the root directory of the current worktree
+    WorktreeRoot,
+}
+
+#[derive(Subcommand)]
+enum ConfigCommands {
+    /// Open the config file in $EDITOR and validate it on save
+    Edit,
+    /// Check the config file and the repository's .groverc for mistakes
+    Validate,
+}
+
+#[derive(Subcommand)]
+enum HooksCommands {
+    /// Run a hook in a worktree without creating anything
+    Run {
+        /// Hook to run (currently only 'bootstrap')
+        name: String,
+        /// Worktree to run it in (defaults to the current worktree)
+        worktree: Option<String>,
     },
 }
 
@@ -194,37 +599,243 @@ fn main() {
         }
     };
 
+    if let Some(path) = cli.config_path {
+        set_config_path(path);
+    }
+    if cli.timing {
+        timing::enable();
+    }
+
     match cli.command {
-        Some(Commands::Add { name, track }) => {
-            commands::add::run(name.as_deref(), track.as_deref());
+        Some(Commands::Add {
+            name,
+            track,
+            at,
+            force,
+            issue,
+            fetch,
+            copy_from,
+            template,
+            no_hooks,
+            open_pr_url,
+            quiet,
+            from,
+            dry_run,
+            from_stash,
+            pop,
+            push,
+            lock,
+            reason,
+        }) => {
+            commands::add::run(&AddOptions {
+                name,
+                track,
+                at,
+                force,
+                issue,
+                fetch,
+                copy_from,
+                template,
+                no_hooks,
+                open_pr_url,
+                quiet,
+                from,
+                dry_run,
+                from_stash,
+                pop,
+                push,
+                lock,
+                lock_reason: reason,
+            });
         }
-        Some(Commands::Go { name, path_only }) => {
-            commands::go::run(name.as_deref(), path_only);
+        Some(Commands::Config { command }) => match command {
+            ConfigCommands::Edit => commands::config::edit(),
+            ConfigCommands::Validate => commands::config::validate(),
+        },
+        Some(Commands::Doctor { fix, json }) => {
+            commands::doctor::run(fix, json);
         }
-        Some(Commands::Init { git_url }) => {
-            commands::init::run(&git_url);
+        Some(Commands::Export) => {
+            commands::export::run();
+        }
+        Some(Commands::Gc { aggressive, prune }) => {
+            commands::gc::run(aggressive, prune.as_deref());
+        }
+        Some(Commands::Go {
+            name,
+            path_only,
+            create,
+            branch,
+            path,
+        }) => {
+            commands::go::run(name.as_deref(), path_only, create, match_by(branch, path));
+        }
+        Some(Commands::Hooks { command }) => match command {
+            HooksCommands::Run { name, worktree } => {
+                commands::hooks::run(&name, worktree.as_deref());
+            }
+        },
+        Some(Commands::Info { json }) => {
+            commands::info::run(json);
+        }
+        Some(Commands::Init {
+            git_url,
+            checkout_default,
+            no_checkout_default: _,
+        }) => {
+            commands::init::run(&git_url, checkout_default);
         }
         Some(Commands::List {
             details,
             dirty,
             locked,
+            locked_reason,
+            dangling,
+            all,
+            main_only,
+            ignore_submodules,
+            no_dirty_check,
+            dirty_check,
+            no_header,
+            dirty_files,
+            since,
+            remote_ahead,
+            external,
+            newer_than,
+            activity,
+            order_added,
+            behind,
+            behind_only,
+            base,
+            author,
+            committer,
+            fields,
+            relative_to,
+            path_only,
+            count,
+            created,
+            limit,
+            offset,
+            compact,
+            wide,
             json,
+            ahead_behind,
+            jsonl,
         }) => {
-            commands::list::run(details, dirty, locked, json);
+            commands::list::run(&WorktreeListOptions {
+                relative_to: relative_to.as_deref().map(absolute_path),
+                dirty,
+                locked,
+                locked_reason,
+                dangling,
+                all,
+                main_only,
+                ignore_submodules,
+                // Filters and columns built on dirty status always need it.
+                dirty_check: dirty_check
+                    || dirty
+                    || dirty_files
+                    || ignore_submodules
+                    || (!no_dirty_check && read_config().list_dirty_check.unwrap_or(true)),
+                dirty_files,
+                since,
+                remote_ahead,
+                external,
+                newer_than,
+                activity,
+                order_added,
+                behind,
+                behind_only,
+                base,
+                author,
+                committer,
+                fields,
+                path_only,
+                count,
+                absolute_created: created == "absolute",
+                limit,
+                offset,
+                details,
+                header: !no_header,
+                spacing: if compact {
+                    TableSpacing::Compact
+                } else if wide {
+                    TableSpacing::Wide
+                } else {
+                    TableSpacing::Normal
+                },
+                ahead_behind,
+                json,
+                jsonl,
+            });
+        }
+        Some(Commands::MvBranch { name, branch }) => {
+            commands::mv_branch::run(&name, &branch);
         }
         Some(Commands::Pr { pr_number }) => {
             commands::pr::run(pr_number);
         }
         Some(Commands::Prune {
             dry_run,
+            report_only,
             force,
+            force_dirty,
             base,
             older_than,
+            since_last_commit,
+            min_age,
+            include_detached,
+            remove_branch,
+            parallel,
+            log,
+            size,
+            created,
         }) => {
-            commands::prune::run(dry_run, force, base.as_deref(), older_than.as_deref());
+            commands::prune::run(&PruneOptions {
+                dry_run,
+                report_only,
+                force,
+                force_dirty,
+                base_branches: base,
+                older_than,
+                since_last_commit,
+                min_age,
+                include_detached,
+                remove_branch,
+                parallel,
+                log,
+                size,
+                absolute_created: created == "absolute",
+            });
         }
-        Some(Commands::Remove { names, force, yes }) => {
-            commands::remove::run(&names, force, yes);
+        Some(Commands::Rebase { name, all, onto }) => {
+            commands::rebase::run(name.as_deref(), all, onto.as_deref());
+        }
+        Some(Commands::RelocateRoot {
+            directory,
+            force,
+            update_ide,
+        }) => {
+            commands::relocate_root::run(&directory, force, update_ide);
+        }
+        Some(Commands::Remove {
+            names,
+            force,
+            yes,
+            keep_branch: _,
+            delete_branch,
+            branch,
+            path,
+        }) => {
+            commands::remove::run(&names, force, yes, delete_branch, match_by(branch, path));
+        }
+        Some(Commands::Reset {
+            name,
+            to,
+            force,
+            allow_main,
+        }) => {
+            commands::reset::run(&name, to.as_deref(), force, allow_main);
         }
         Some(Commands::SelfUpdate { version, pr }) => {
             commands::self_update::run(version.as_deref(), pr);
@@ -232,8 +843,22 @@ fn main() {
         Some(Commands::ShellInit { shell }) => {
             commands::shell_init::run(&shell);
         }
-        Some(Commands::Sync { branch }) => {
-            commands::sync::run(branch.as_deref());
+        Some(Commands::Status { short, json, all }) => {
+            commands::status::run(short, json, all);
+        }
+        Some(Commands::Sync {
+            branch,
+            inventory,
+            jobs,
+        }) => match inventory {
+            Some(file) => commands::sync::restore(&file, jobs),
+            None => commands::sync::run(branch.as_deref()),
+        },
+        Some(Commands::Uninit { force, yes }) => {
+            commands::uninit::run(force, yes);
+        }
+        Some(Commands::WorktreeRoot) => {
+            commands::worktree_root::run();
         }
         None => {
             // No command provided - show help
@@ -248,8 +873,11 @@ fn main() {
 
 #[cfg(test)]
 mod tests {
-    use super::{validate_branch_name, validate_tracking_reference, Cli, Commands};
+    use super::{
+        match_by, validate_branch_name, validate_tracking_reference, Cli, Commands, MatchBy,
+    };
     use clap::Parser;
+    use std::path::PathBuf;
 
     #[test]
     fn validate_branch_name_trims_trailing_slashes() {
@@ -277,13 +905,151 @@ mod tests {
         assert!(validate_tracking_reference("origin/feature//my-branch").is_err());
     }
 
+    #[test]
+    fn add_issue_accepts_hash_prefix_and_conflicts_with_name() {
+        let cli = Cli::try_parse_from(["grove", "add", "--issue", "#42"]).unwrap();
+        match cli.command {
+            Some(Commands::Add { issue, .. }) => assert_eq!(issue, Some(42)),
+            _ => panic!("Expected Add command"),
+        }
+        assert!(Cli::try_parse_from(["grove", "add", "--issue", "0"]).is_err());
+        assert!(Cli::try_parse_from(["grove", "add", "feature", "--issue", "42"]).is_err());
+    }
+
+    #[test]
+    fn prune_include_detached_requires_older_than() {
+        assert!(Cli::try_parse_from(["grove", "prune", "--include-detached"]).is_err());
+        assert!(Cli::try_parse_from([
+            "grove",
+            "prune",
+            "--include-detached",
+            "--older-than",
+            "30d"
+        ])
+        .is_ok());
+    }
+
+    #[test]
+    fn config_flag_is_global() {
+        let cli = Cli::try_parse_from(["grove", "--config", "/tmp/a.json", "list"]).unwrap();
+        assert_eq!(cli.config_path, Some(PathBuf::from("/tmp/a.json")));
+        let cli = Cli::try_parse_from(["grove", "config", "edit", "--config", "b.json"]).unwrap();
+        assert_eq!(cli.config_path, Some(PathBuf::from("b.json")));
+    }
+
+    #[test]
+    fn remove_keep_branch_conflicts_with_delete_branch() {
+        let cli = Cli::try_parse_from(["grove", "remove", "feature"]).unwrap();
+        assert!(matches!(
+            cli.command,
+            Some(Commands::Remove {
+                delete_branch: false,
+                ..
+            })
+        ));
+        assert!(Cli::try_parse_from(["grove", "remove", "x", "--keep-branch"]).is_ok());
+        assert!(Cli::try_parse_from(["grove", "remove", "x", "--delete-branch"]).is_ok());
+        assert!(
+            Cli::try_parse_from(["grove", "remove", "x", "--keep-branch", "--delete-branch"])
+                .is_err()
+        );
+    }
+
+    #[test]
+    fn list_base_requires_behind() {
+        assert!(Cli::try_parse_from(["grove", "list", "--base", "main"]).is_err());
+        assert!(Cli::try_parse_from(["grove", "list", "--behind", "--base", "main"]).is_ok());
+        assert!(Cli::try_parse_from(["grove", "list", "--behind-only", "--base", "main"]).is_ok());
+    }
+
+    #[test]
+    fn prune_since_last_commit_conflicts_with_older_than() {
+        assert!(Cli::try_parse_from(["grove", "prune", "--since-last-commit", "30d"]).is_ok());
+        assert!(Cli::try_parse_from(["grove", "prune", "--since-last-commit", "soon"]).is_err());
+        assert!(Cli::try_parse_from([
+            "grove",
+            "prune",
+            "--since-last-commit",
+            "30d",
+            "--older-than",
+            "30d"
+        ])
+        .is_err());
+    }
+
+    #[test]
+    fn prune_parallel_defaults_to_four_jobs() {
+        let parallel = |args: &[&str]| match Cli::try_parse_from(args).unwrap().command {
+            Some(Commands::Prune { parallel, .. }) => parallel,
+            _ => panic!("expected prune command"),
+        };
+        assert_eq!(parallel(&["grove", "prune"]), None);
+        assert_eq!(parallel(&["grove", "prune", "--parallel"]), Some(4));
+        assert_eq!(parallel(&["grove", "prune", "--parallel", "8"]), Some(8));
+        assert!(Cli::try_parse_from(["grove", "prune", "--parallel", "0"]).is_err());
+    }
+
+    #[test]
+    fn go_accepts_dash_for_previous_worktree() {
+        let cli = Cli::try_parse_from(["grove", "go", "-"]).unwrap();
+        match cli.command {
+            Some(Commands::Go { name, .. }) => assert_eq!(name.as_deref(), Some("-")),
+            _ => panic!("expected go command"),
+        }
+    }
+
+    #[test]
+    fn branch_and_path_matching_are_exclusive() {
+        assert!(Cli::try_parse_from(["grove", "go", "x", "--branch"]).is_ok());
+        assert!(Cli::try_parse_from(["grove", "go", "x", "--branch", "--path"]).is_err());
+        assert!(Cli::try_parse_from(["grove", "go", "x", "--path", "--create"]).is_err());
+        assert!(Cli::try_parse_from(["grove", "remove", "x", "--branch", "--path"]).is_err());
+        assert_eq!(match_by(false, false), MatchBy::Any);
+        assert_eq!(match_by(false, true), MatchBy::Path);
+    }
+
     #[test]
     fn add_command_allows_omitted_name() {
         let cli = Cli::try_parse_from(["grove", "add"]).unwrap();
         match cli.command {
-            Some(Commands::Add { name, track }) => {
+            Some(Commands::Add {
+                name,
+                track,
+                at,
+                force,
+                issue,
+                fetch,
+                copy_from,
+                template,
+                no_hooks,
+                open_pr_url,
+                quiet,
+                from,
+                dry_run,
+                from_stash,
+                pop,
+                push,
+                lock,
+                reason,
+            }) => {
+                assert!(!fetch);
+                assert!(!dry_run);
+                assert!(from_stash.is_none());
+                assert!(!pop);
+                assert!(!push);
+                assert!(!lock);
+                assert!(reason.is_none());
+                assert!(!quiet);
+                assert!(from.is_none());
+                assert!(copy_from.is_none());
+                assert!(template.is_none());
+                assert!(!no_hooks);
+                assert!(!open_pr_url);
                 assert!(name.is_none());
                 assert!(track.is_none());
+                assert!(at.is_none());
+                assert!(!force);
+                assert!(issue.is_none());
             }
             _ => panic!("expected add command"),
         }
@@ -300,7 +1066,7 @@ mod tests {
         ])
         .unwrap();
         match cli.command {
-            Some(Commands::Add { name, track }) => {
+            Some(Commands::Add { name, track, .. }) => {
                 assert_eq!(name.as_deref(), Some("feature/new-worktree"));
                 assert_eq!(track.as_deref(), Some("origin/main"));
             }
@@ -308,6 +1074,32 @@ mod tests {
         }
     }
 
+    #[test]
+    fn add_command_from_stash_takes_a_value_only_with_equals() {
+        let cli = Cli::try_parse_from(["grove", "add", "--from-stash", "feature-x"]).unwrap();
+        match cli.command {
+            Some(Commands::Add {
+                name, from_stash, ..
+            }) => {
+                assert_eq!(name.as_deref(), Some("feature-x"));
+                assert_eq!(from_stash.as_deref(), Some("stash@{0}"));
+            }
+            _ => panic!("expected add command"),
+        }
+
+        let cli =
+            Cli::try_parse_from(["grove", "add", "feature-x", "--from-stash=stash@{2}"]).unwrap();
+        match cli.command {
+            Some(Commands::Add {
+                name, from_stash, ..
+            }) => {
+                assert_eq!(name.as_deref(), Some("feature-x"));
+                assert_eq!(from_stash.as_deref(), Some("stash@{2}"));
+            }
+            _ => panic!("expected add command"),
+        }
+    }
+
     #[test]
     fn add_command_normalizes_track_input() {
         let cli = Cli::try_parse_from([
@@ -12,22 +12,179 @@ pub struct Worktree {
     pub is_dirty: bool,
     #[serde(rename = "isLocked")]
     pub is_locked: bool,
+    /// The reason given to `git worktree lock --reason`, if any.
+    #[serde(rename = "lockReason", skip_serializing_if = "Option::is_none")]
+    pub lock_reason: Option<String>,
     #[serde(rename = "isPrunable")]
     pub is_prunable: bool,
     #[serde(rename = "isMain")]
     pub is_main: bool,
+    /// The worktree is checked out on a branch whose ref no longer exists.
+    #[serde(rename = "isDangling")]
+    pub is_dangling: bool,
+}
+
+impl Worktree {
+    /// Comma-separated state of the worktree, e.g. `dirty, locked`.
+    pub fn status_label(&self) -> String {
+        self.status_label_with(Some(self.is_dirty))
+    }
+
+    /// Like `status_label`; `unknown` stands in for clean or dirty when
+    /// `is_dirty` is `None`.
+    pub fn status_label_with(&self, is_dirty: Option<bool>) -> String {
+        let mut statuses = vec![match is_dirty {
+            Some(true) => "dirty",
+            Some(false) => "clean",
+            None => "unknown",
+        }];
+        if self.is_locked {
+            statuses.push("locked");
+        }
+        if self.is_prunable {
+            statuses.push("prunable");
+        }
+        if self.is_dangling {
+            statuses.push("dangling");
+        }
+        statuses.join(", ")
+    }
+}
+
+pub struct AddOptions {
+    pub name: Option<String>,
+    pub track: Option<String>,
+    pub at: Option<String>,
+    pub force: bool,
+    pub issue: Option<u64>,
+    pub fetch: bool,
+    pub copy_from: Option<String>,
+    /// Directory whose contents are copied into the new worktree; overrides
+    /// worktreeTemplateDir.
+    pub template: Option<String>,
+    /// Skip the bootstrap commands from .groverc.
+    pub no_hooks: bool,
+    /// Print the URL for opening a pull request from the new branch.
+    pub open_pr_url: bool,
+    /// Print only the new worktree's path on stdout; everything else goes to stderr.
+    pub quiet: bool,
+    /// Start a new branch here instead of at the default branch.
+    pub from: Option<String>,
+    /// Resolve everything and print the plan without creating the worktree.
+    pub dry_run: bool,
+    /// Stash to apply in the new worktree once it exists.
+    pub from_stash: Option<String>,
+    /// Drop the stash after applying it cleanly.
+    pub pop: bool,
+    /// Push the branch to origin and track it once the worktree exists.
+    pub push: bool,
+    /// Lock the worktree as soon as it is created.
+    pub lock: bool,
+    pub lock_reason: Option<String>,
+}
+
+/// How much room `grove list` leaves between table columns.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq)]
+pub enum TableSpacing {
+    /// Columns only as wide as their longest cell, one space apart.
+    Compact,
+    #[default]
+    Normal,
+    /// Four spaces between columns.
+    Wide,
+}
+
+impl TableSpacing {
+    pub fn gap(self) -> &'static str {
+        match self {
+            TableSpacing::Compact => " ",
+            TableSpacing::Normal => "  ",
+            TableSpacing::Wide => "    ",
+        }
+    }
 }
 
 pub struct WorktreeListOptions {
+    /// Show paths relative to this absolute directory instead of in full.
+    pub relative_to: Option<std::path::PathBuf>,
     pub dirty: bool,
     pub locked: bool,
+    /// Only locked worktrees whose lock reason contains this, ignoring case.
+    pub locked_reason: Option<String>,
+    pub dangling: bool,
+    /// Include worktrees hidden by `.groveignore`.
+    pub all: bool,
+    /// Only the primary worktree; see `list::main_worktree`.
+    pub main_only: bool,
+    /// Leave submodule changes out of dirty detection.
+    pub ignore_submodules: bool,
+    /// Run `git status` in each worktree; without it every worktree's dirty
+    /// status is unknown.
+    pub dirty_check: bool,
+    /// Show staged/unstaged/untracked file counts for dirty worktrees.
+    pub dirty_files: bool,
+    /// Show how many commits each branch has that aren't in this revision.
+    pub since: Option<String>,
+    /// Only worktrees whose branch has commits its upstream doesn't.
+    pub remote_ahead: bool,
+    /// Only worktrees outside the project root, marked `external` in the status column.
+    pub external: bool,
+    /// Only worktrees created within this duration; validated by clap.
+    pub newer_than: Option<String>,
+    /// Sort by most recent activity and show when each worktree was last active.
+    pub activity: bool,
+    /// Sort by when each worktree was registered with git, oldest first.
+    pub order_added: bool,
+    /// Show how many commits each branch is behind `base`.
+    pub behind: bool,
+    /// Like `behind`, but hide worktrees that are already up to date.
+    pub behind_only: bool,
+    /// Revision for `behind`; the default branch when unset.
+    pub base: Option<String>,
+    pub author: Option<String>,
+    pub committer: Option<String>,
+    pub fields: Option<String>,
+    pub path_only: bool,
+    /// Print only how many worktrees match the filters.
+    pub count: bool,
+    /// Show exact creation timestamps instead of relative times.
+    pub absolute_created: bool,
+    /// Page through matching worktrees after filtering and sorting.
+    pub limit: Option<usize>,
+    pub offset: Option<usize>,
     pub details: bool,
+    /// Print the project root, bare clone, and default branch above the list.
+    pub header: bool,
+    pub spacing: TableSpacing,
+    /// Add `upstream`, `ahead`, and `behind` to JSON output.
+    pub ahead_behind: bool,
+    pub json: bool,
+    pub jsonl: bool,
 }
 
-#[allow(dead_code)]
 pub struct PruneOptions {
     pub dry_run: bool,
+    /// List candidates without prompting or removing, and exit non-zero if
+    /// there are any.
+    pub report_only: bool,
     pub force: bool,
-    pub base_branch: String,
-    pub older_than: Option<u64>, // Age threshold in milliseconds
+    /// Skip confirmation for worktrees with uncommitted changes, keeping every
+    /// other guard that `force` lifts.
+    pub force_dirty: bool,
+    /// A branch merged into any of these counts as merged; the default branch when empty.
+    pub base_branches: Vec<String>,
+    pub older_than: Option<String>, // Duration string, validated by clap
+    pub since_last_commit: Option<String>, // Duration string, validated by clap
+    /// Never prune worktrees created more recently than this, unless `force`.
+    pub min_age: Option<String>, // Duration string, validated by clap
+    pub include_detached: bool,
+    pub remove_branch: bool,
+    /// Number of worktrees to remove concurrently; `None` removes them one at a time.
+    pub parallel: Option<usize>,
+    /// File to append a JSON line to for each removed worktree.
+    pub log: Option<String>,
+    /// Show each candidate's disk usage and the total that would be freed.
+    pub size: bool,
+    /// Show exact creation timestamps instead of dates.
+    pub absolute_created: bool,
 }
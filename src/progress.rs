@@ -0,0 +1,75 @@
+use std::io::{self, Write};
+
+/// Reports `[current/total] action item` lines while a bulk operation runs.
+/// Output goes to stderr so it never mixes with machine-readable stdout.
+pub struct Progress<W: Write> {
+    writer: W,
+    total: usize,
+    current: usize,
+    enabled: bool,
+}
+
+impl Progress<io::Stderr> {
+    /// Progress on stderr, shown only when stderr is a terminal and the
+    /// command is not producing JSON.
+    pub fn stderr(total: usize, json_output: bool) -> Self {
+        let enabled = !json_output && atty::is(atty::Stream::Stderr);
+        Progress::new(io::stderr(), total, enabled)
+    }
+}
+
+impl<W: Write> Progress<W> {
+    pub fn new(writer: W, total: usize, enabled: bool) -> Self {
+        Progress {
+            writer,
+            total,
+            current: 0,
+            enabled,
+        }
+    }
+
+    /// Advance to the next item and print its progress line.
+    pub fn step(&mut self, action: &str, item: &str) {
+        self.current += 1;
+        if !self.enabled {
+            return;
+        }
+        // Progress output is best-effort; a closed stderr must not abort the operation.
+        let _ = writeln!(
+            self.writer,
+            "[{}/{}] {} {}",
+            self.current, self.total, action, item
+        );
+    }
+
+    #[cfg(test)]
+    pub fn into_inner(self) -> W {
+        self.writer
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn step_prints_counter_and_item() {
+        let mut progress = Progress::new(Vec::new(), 2, true);
+        progress.step("removing", "feature-x");
+        progress.step("removing", "feature-y");
+
+        let output = String::from_utf8(progress.into_inner()).unwrap();
+        assert_eq!(
+            output,
+            "[1/2] removing feature-x\n[2/2] removing feature-y\n"
+        );
+    }
+
+    #[test]
+    fn disabled_progress_prints_nothing() {
+        let mut progress = Progress::new(Vec::new(), 1, false);
+        progress.step("removing", "feature-x");
+
+        assert!(progress.into_inner().is_empty());
+    }
+}
@@ -0,0 +1,148 @@
+use colored::Colorize;
+use std::io::{self, BufRead, Write};
+
+use crate::models::Worktree;
+
+/// Ask the user to pick one of `worktrees`: a type-to-search list when stderr
+/// is a terminal, otherwise the numbered list from `pick_worktree`. Returns
+/// `Ok(None)` when the selection is cancelled.
+pub fn choose_worktree<'a>(
+    worktrees: &'a [&'a Worktree],
+    prompt: &str,
+) -> Result<Option<&'a Worktree>, String> {
+    if !atty::is(atty::Stream::Stdin) {
+        return Err("Interactive selection requires a TTY.".to_string());
+    }
+    if !atty::is(atty::Stream::Stderr) {
+        return pick_worktree(worktrees, prompt);
+    }
+
+    let items: Vec<String> = worktrees
+        .iter()
+        .map(|wt| format!("{} ({})", wt.branch, wt.path))
+        .collect();
+    let selection = dialoguer::FuzzySelect::new()
+        .with_prompt(format!("{} (type to search)", prompt))
+        .items(&items)
+        .interact_opt()
+        .map_err(|e| format!("Failed to read selection: {}", e))?;
+    Ok(selection.map(|index| worktrees[index]))
+}
+
+/// Ask the user to pick one of `worktrees` from a numbered list read from
+/// stdin. Returns `Ok(None)` when the selection is cancelled with an empty
+/// line or EOF.
+///
+/// The list and prompt go to stderr so `grove go --path-only` can still be
+/// captured by shell integration.
+pub fn pick_worktree<'a>(
+    worktrees: &'a [&'a Worktree],
+    prompt: &str,
+) -> Result<Option<&'a Worktree>, String> {
+    if !atty::is(atty::Stream::Stdin) {
+        return Err("Interactive selection requires a TTY.".to_string());
+    }
+
+    let stdin = io::stdin();
+    select_worktree(&mut stdin.lock(), &mut io::stderr(), worktrees, prompt)
+}
+
+fn select_worktree<'a, R: BufRead, W: Write>(
+    input: &mut R,
+    output: &mut W,
+    worktrees: &'a [&'a Worktree],
+    prompt: &str,
+) -> Result<Option<&'a Worktree>, String> {
+    let write_error = |e: io::Error| format!("Failed to show worktree list: {}", e);
+
+    for (index, wt) in worktrees.iter().enumerate() {
+        writeln!(
+            output,
+            "  {:>2}) {} {} {}",
+            index + 1,
+            wt.branch.bold(),
+            format!("[{}]", wt.status_label()).dimmed(),
+            wt.path.dimmed()
+        )
+        .map_err(write_error)?;
+    }
+
+    loop {
+        write!(output, "{} [1-{}]: ", prompt, worktrees.len()).map_err(write_error)?;
+        output.flush().map_err(write_error)?;
+
+        let mut line = String::new();
+        let read = input
+            .read_line(&mut line)
+            .map_err(|e| format!("Failed to read selection: {}", e))?;
+        let answer = line.trim();
+        if read == 0 || answer.is_empty() {
+            return Ok(None);
+        }
+
+        match answer.parse::<usize>() {
+            Ok(n) if (1..=worktrees.len()).contains(&n) => return Ok(Some(worktrees[n - 1])),
+            _ => writeln!(
+                output,
+                "{}",
+                format!("Enter a number between 1 and {}.", worktrees.len()).yellow()
+            )
+            .map_err(write_error)?,
+        }
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+    use chrono::DateTime;
+
+    fn make_worktree(branch: &str) -> Worktree {
+        Worktree {
+            path: format!("/repo/{}", branch),
+            branch: branch.to_string(),
+            head: "abc123".to_string(),
+            created_at: DateTime::from_timestamp(0, 0).unwrap(),
+            is_dirty: false,
+            is_locked: false,
+            lock_reason: None,
+            is_prunable: false,
+            is_main: false,
+            is_dangling: false,
+        }
+    }
+
+    #[test]
+    fn numeric_selection_returns_that_worktree() {
+        let worktrees = [make_worktree("feature-a"), make_worktree("feature-b")];
+        let choices: Vec<&Worktree> = worktrees.iter().collect();
+        let mut output = Vec::new();
+
+        let picked = select_worktree(&mut "2\n".as_bytes(), &mut output, &choices, "Select")
+            .unwrap()
+            .unwrap();
+        assert_eq!(picked.branch, "feature-b");
+
+        let output = String::from_utf8(output).unwrap();
+        assert!(output.contains("1) "));
+        assert!(output.contains("feature-a"));
+        assert!(output.contains("[clean]"));
+    }
+
+    #[test]
+    fn invalid_selection_asks_again_and_empty_cancels() {
+        let worktrees = [make_worktree("feature-a")];
+        let choices: Vec<&Worktree> = worktrees.iter().collect();
+        let mut output = Vec::new();
+
+        let picked =
+            select_worktree(&mut "7\nx\n1\n".as_bytes(), &mut output, &choices, "Select").unwrap();
+        assert_eq!(picked.map(|wt| wt.branch.as_str()), Some("feature-a"));
+        let output = String::from_utf8(output).unwrap();
+        assert_eq!(output.matches("Enter a number between 1 and 1.").count(), 2);
+
+        let cancelled =
+            select_worktree(&mut "\n".as_bytes(), &mut Vec::new(), &choices, "Select").unwrap();
+        assert!(cancelled.is_none());
+    }
+}
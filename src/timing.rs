@@ -0,0 +1,69 @@
+use std::io::{self, Write};
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::time::{Duration, Instant};
+
+/// Set by the global `--timing` flag.
+static ENABLED: AtomicBool = AtomicBool::new(false);
+
+pub fn enable() {
+    ENABLED.store(true, Ordering::Relaxed);
+}
+
+/// Run `f` and, when `--timing` is on, report how long it took on stderr.
+pub fn time<T>(phase: &str, f: impl FnOnce() -> T) -> T {
+    time_to(&mut io::stderr(), ENABLED.load(Ordering::Relaxed), phase, f)
+}
+
+fn time_to<W: Write, T>(writer: &mut W, enabled: bool, phase: &str, f: impl FnOnce() -> T) -> T {
+    if !enabled {
+        return f();
+    }
+    let start = Instant::now();
+    let result = f();
+    // Timing output is diagnostic; a closed stderr must not abort the command.
+    let _ = writeln!(writer, "{}", format_timing(phase, start.elapsed()));
+    result
+}
+
+fn format_timing(phase: &str, elapsed: Duration) -> String {
+    format!(
+        "[timing] {}: {:.1}ms",
+        phase,
+        elapsed.as_secs_f64() * 1000.0
+    )
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn enabled_timer_reports_each_phase() {
+        let mut output = Vec::new();
+        let value = time_to(&mut output, true, "git worktree list", || 42);
+        time_to(&mut output, true, "dirty checks", || ());
+        assert_eq!(value, 42);
+
+        let output = String::from_utf8(output).unwrap();
+        let lines: Vec<&str> = output.lines().collect();
+        assert_eq!(lines.len(), 2);
+        assert!(lines[0].starts_with("[timing] git worktree list: "));
+        assert!(lines[0].ends_with("ms"));
+        assert!(lines[1].starts_with("[timing] dirty checks: "));
+    }
+
+    #[test]
+    fn disabled_timer_prints_nothing() {
+        let mut output = Vec::new();
+        time_to(&mut output, false, "git worktree list", || ());
+        assert!(output.is_empty());
+    }
+
+    #[test]
+    fn format_timing_uses_milliseconds() {
+        assert_eq!(
+            format_timing("repo discovery", Duration::from_micros(1500)),
+            "[timing] repo discovery: 1.5ms"
+        );
+    }
+}